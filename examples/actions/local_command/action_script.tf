@@ -0,0 +1,20 @@
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.script_example]
+    }
+  }
+}
+
+action "local_command" "script_example" {
+  config {
+    interpreter = ["bash", "-eu"]
+    arguments   = ["alice", "bob"]
+    script      = <<-EOT
+      for name in "$@"; do
+        echo "hello, $name"
+      done
+    EOT
+  }
+}
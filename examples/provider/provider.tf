@@ -0,0 +1,12 @@
+// Restrict the files that can be managed or read by this provider
+// to the directory containing the root module and a scratch directory,
+// and only allow `local_command` to run a couple of well-known tools.
+provider "local" {
+  allowed_paths = [
+    path.root,
+    "/tmp/terraform",
+  ]
+
+  allowed_commands = ["jq", "/usr/local/bin/*"]
+  denied_commands  = ["curl", "wget"]
+}
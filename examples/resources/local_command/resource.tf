@@ -0,0 +1,28 @@
+// Manages a Docker network with the Docker CLI, replacing a `null_resource`
+// with `local-exec` create and destroy provisioners.
+resource "local_command" "network" {
+  triggers = {
+    name = "example"
+  }
+
+  create {
+    command   = "docker"
+    arguments = ["network", "create", "example"]
+  }
+
+  # The network ID written by the create command is passed to standard input,
+  # and a non-zero exit code means the network no longer exists.
+  read {
+    command   = "bash"
+    arguments = ["-c", "docker network inspect --format '{{.Id}}' \"$(cat)\""]
+  }
+
+  destroy {
+    command   = "bash"
+    arguments = ["-c", "docker network rm \"$(cat)\""]
+  }
+}
+
+output "network_id" {
+  value = trimspace(local_command.network.stdout)
+}
@@ -0,0 +1,13 @@
+// Archives a source directory, streaming the archive straight to disk rather
+// than storing it in state.
+resource "local_command_output_file" "archive" {
+  filename        = "${path.module}/build/source.tar.gz"
+  file_permission = "0644"
+
+  command   = "tar"
+  arguments = ["-czf", "-", "-C", "${path.module}/src", "."]
+
+  triggers = {
+    source_hash = sha256(join("", [for f in fileset("${path.module}/src", "**") : filesha256("${path.module}/src/${f}")]))
+  }
+}
@@ -0,0 +1,4 @@
+import {
+  to = local_sensitive_file.foo
+  id = "/path/to/foo.bar"
+}
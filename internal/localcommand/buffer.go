@@ -0,0 +1,120 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OutputTruncation is the policy applied when a command writes more than
+// MaxOutputBytes to one of its output streams.
+type OutputTruncation string
+
+const (
+	// OutputTruncationError stops the command and returns an error
+	// diagnostic, keeping the first MaxOutputBytes of output.
+	OutputTruncationError OutputTruncation = "error"
+
+	// OutputTruncationHead discards the beginning of the output, keeping the
+	// last MaxOutputBytes.
+	OutputTruncationHead OutputTruncation = "truncate_head"
+
+	// OutputTruncationTail discards the end of the output, keeping the first
+	// MaxOutputBytes.
+	OutputTruncationTail OutputTruncation = "truncate_tail"
+)
+
+// OutputTruncations returns the supported OutputTruncation values, for use in
+// schema validation and documentation.
+func OutputTruncations() []string {
+	return []string{
+		string(OutputTruncationError),
+		string(OutputTruncationHead),
+		string(OutputTruncationTail),
+	}
+}
+
+// maxLogOutputBytes caps the output included in trace logs, independently of
+// MaxOutputBytes, so that logs stay readable.
+const maxLogOutputBytes = 4096
+
+// logOutput returns data for inclusion in trace logs, truncated to
+// maxLogOutputBytes.
+func logOutput(data []byte) string {
+	if len(data) <= maxLogOutputBytes {
+		return string(data)
+	}
+
+	return fmt.Sprintf("%s... (%d more bytes not logged)", data[:maxLogOutputBytes], len(data)-maxLogOutputBytes)
+}
+
+// outputBuffer captures an output stream of a command, holding at most limit
+// bytes if limit is greater than zero. Writes never fail, so the command is
+// not disrupted by output being discarded.
+type outputBuffer struct {
+	limit      int64
+	truncation OutputTruncation
+
+	// exceeded is closed once a buffer using OutputTruncationError exceeds
+	// its limit, so that the command can be stopped.
+	exceeded     chan struct{}
+	exceededOnce *sync.Once
+
+	data      []byte
+	truncated bool
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		b.data = append(b.data, p...)
+		return len(p), nil
+	}
+
+	limit := int(b.limit)
+
+	switch b.truncation {
+	case OutputTruncationHead:
+		b.data = append(b.data, p...)
+
+		// Compact only once the buffer holds twice the limit, so that
+		// copying is amortized across writes.
+		if len(b.data) > 2*limit {
+			b.data = append(b.data[:0], b.data[len(b.data)-limit:]...)
+			b.truncated = true
+		}
+	default:
+		remaining := limit - len(b.data)
+		if remaining >= len(p) {
+			b.data = append(b.data, p...)
+			break
+		}
+
+		if remaining > 0 {
+			b.data = append(b.data, p[:remaining]...)
+		}
+
+		b.truncated = true
+
+		if b.truncation == OutputTruncationError {
+			b.exceededOnce.Do(func() { close(b.exceeded) })
+		}
+	}
+
+	return len(p), nil
+}
+
+// Bytes returns the captured output.
+func (b *outputBuffer) Bytes() []byte {
+	if b.truncation == OutputTruncationHead && b.limit > 0 && len(b.data) > int(b.limit) {
+		return b.data[len(b.data)-int(b.limit):]
+	}
+
+	return b.data
+}
+
+// Truncated reports whether any output was discarded.
+func (b *outputBuffer) Truncated() bool {
+	return b.truncated || (b.limit > 0 && len(b.data) > int(b.limit))
+}
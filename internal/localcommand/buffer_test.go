@@ -0,0 +1,119 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		limit             int64
+		truncation        OutputTruncation
+		writes            []string
+		expected          string
+		expectedTruncated bool
+		expectedExceeded  bool
+	}{
+		"unlimited": {
+			truncation: OutputTruncationError,
+			writes:     []string{"hello ", "world"},
+			expected:   "hello world",
+		},
+		"within-limit": {
+			limit:      11,
+			truncation: OutputTruncationError,
+			writes:     []string{"hello ", "world"},
+			expected:   "hello world",
+		},
+		"error": {
+			limit:             8,
+			truncation:        OutputTruncationError,
+			writes:            []string{"hello ", "world"},
+			expected:          "hello wo",
+			expectedTruncated: true,
+			expectedExceeded:  true,
+		},
+		"truncate-tail": {
+			limit:             8,
+			truncation:        OutputTruncationTail,
+			writes:            []string{"hello ", "world", "!"},
+			expected:          "hello wo",
+			expectedTruncated: true,
+		},
+		"truncate-head": {
+			limit:             8,
+			truncation:        OutputTruncationHead,
+			writes:            []string{"hello ", "world", "!"},
+			expected:          "o world!",
+			expectedTruncated: true,
+		},
+		"truncate-head-compacted": {
+			limit:             3,
+			truncation:        OutputTruncationHead,
+			writes:            []string{"abcdefgh", "ij", "klmnop", "q"},
+			expected:          "opq",
+			expectedTruncated: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exceeded := make(chan struct{})
+			buffer := &outputBuffer{
+				limit:        testCase.limit,
+				truncation:   testCase.truncation,
+				exceeded:     exceeded,
+				exceededOnce: &sync.Once{},
+			}
+
+			for _, write := range testCase.writes {
+				n, err := buffer.Write([]byte(write))
+				if err != nil || n != len(write) {
+					t.Fatalf("expected write of %d bytes to succeed, got: %d, %v", len(write), n, err)
+				}
+			}
+
+			if got := string(buffer.Bytes()); got != testCase.expected {
+				t.Errorf("expected %q, got: %q", testCase.expected, got)
+			}
+
+			if got := buffer.Truncated(); got != testCase.expectedTruncated {
+				t.Errorf("expected truncated %t, got: %t", testCase.expectedTruncated, got)
+			}
+
+			select {
+			case <-exceeded:
+				if !testCase.expectedExceeded {
+					t.Error("unexpected exceeded signal")
+				}
+			default:
+				if testCase.expectedExceeded {
+					t.Error("expected exceeded signal")
+				}
+			}
+		})
+	}
+}
+
+func TestLogOutput(t *testing.T) {
+	t.Parallel()
+
+	if got := logOutput([]byte("short")); got != "short" {
+		t.Errorf("expected short output to be logged in full, got: %q", got)
+	}
+
+	got := logOutput([]byte(strings.Repeat("a", maxLogOutputBytes+10)))
+	expected := strings.Repeat("a", maxLogOutputBytes) + "... (10 more bytes not logged)"
+
+	if got != expected {
+		t.Errorf("expected truncated log output, got: %q", got)
+	}
+}
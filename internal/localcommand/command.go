@@ -0,0 +1,842 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package localcommand executes local commands on behalf of the local_command
+// data source, action and ephemeral resource, so that each of them shares the
+// same argument handling, environment handling, output capture and
+// diagnostics.
+package localcommand
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Kind is the kind of Terraform object executing a command, as it is
+// referred to within diagnostics.
+type Kind string
+
+const (
+	KindAction            Kind = "action"
+	KindDataSource        Kind = "data source"
+	KindEphemeralResource Kind = "ephemeral resource"
+	KindResource          Kind = "resource"
+)
+
+// Command describes a single execution of a local executable.
+type Command struct {
+	// Kind is the kind of Terraform object executing the command.
+	Kind Kind
+
+	// Path is the path of the configuration block holding the command
+	// attributes, which diagnostics are reported relative to. If empty, the
+	// attributes are at the root of the schema.
+	Path path.Path
+
+	// Name is the executable name to be discovered on the PATH or a path to
+	// the executable.
+	Name string
+
+	// Arguments are passed to the executable, see the Arguments function.
+	Arguments []string
+
+	// Script, if not empty, is written to a private temporary file which is
+	// run by the Interpreter instead of Name, with the path of the file
+	// passed before Arguments. The file is removed once the command exits.
+	Script string
+
+	// Interpreter is the executable and leading arguments used to run the
+	// Script. If empty, DefaultInterpreter is used.
+	Interpreter []string
+
+	// script reports whether the command runs a Script, so that diagnostics
+	// are reported on the script attribute rather than the command.
+	script bool
+
+	// WorkingDirectory is the directory the executable runs in. If empty,
+	// the Terraform working directory is used.
+	WorkingDirectory string
+
+	// Environment holds variables merged on top of the inherited
+	// environment, with these values taking precedence.
+	Environment map[string]string
+
+	// SensitiveEnvironment holds variables merged on top of the inherited
+	// environment and Environment, with these values taking precedence. Their
+	// values are redacted from logs and diagnostics.
+	SensitiveEnvironment map[string]string
+
+	// InheritEnvironment controls which variables of the environment of the
+	// Terraform process are passed to the executable. If empty,
+	// InheritEnvironmentAll is used.
+	InheritEnvironment InheritEnvironment
+
+	// InheritedEnvironmentVariables are the names of the variables passed to
+	// the executable when InheritEnvironment is InheritEnvironmentAllowlist.
+	InheritedEnvironmentVariables []string
+
+	// RunAs, if not zero, is the user and group the executable runs as
+	// instead of those of the Terraform process.
+	RunAs RunAs
+
+	// Limits, if not nil, are the resource limits applied to the executable.
+	Limits *Limits
+
+	// Sandbox, if not nil, restricts the filesystem access of the
+	// executable.
+	Sandbox *Sandbox
+
+	// Stdin, if not nil, is passed to the standard input of the executable.
+	Stdin []byte
+
+	// Stdout, if set, receives the standard output of the executable instead
+	// of Result.Stdout, so that large or binary output is not held in memory.
+	// The output is then neither limited by MaxOutputBytes, matched by
+	// SuccessStdoutRegex and FailureStdoutRegex, decoded nor passed to
+	// Progress.
+	Stdout OutputWriter
+
+	// AllowNonZeroExitCode prevents a non-zero exit code from being
+	// reported as an error diagnostic.
+	AllowNonZeroExitCode bool
+
+	// SuccessExitCodes are the exit codes indicating that the command
+	// succeeded. If empty, only a zero exit code indicates success.
+	SuccessExitCodes []int
+
+	// SuccessStdoutRegex and SuccessStderrRegex, if set, indicate that the
+	// command succeeded regardless of its exit code when they match the
+	// respective captured output stream.
+	SuccessStdoutRegex *regexp.Regexp
+	SuccessStderrRegex *regexp.Regexp
+
+	// FailureStdoutRegex and FailureStderrRegex, if set, indicate that the
+	// command failed regardless of its exit code when they match the
+	// respective captured output stream. They take precedence over
+	// SuccessExitCodes, SuccessStdoutRegex and SuccessStderrRegex.
+	FailureStdoutRegex *regexp.Regexp
+	FailureStderrRegex *regexp.Regexp
+
+	// Retry, if set, runs the command again when it exits by itself and
+	// fails, see Retry.
+	Retry *Retry
+
+	// OutputFormat, if set, is used to decode the standard output of the
+	// executable into Result.StdoutDecoded.
+	OutputFormat OutputFormat
+
+	// MaxOutputBytes, if greater than zero, limits how many bytes of each of
+	// the standard output and standard error streams are captured.
+	MaxOutputBytes int64
+
+	// OutputTruncation is the policy applied when a stream exceeds
+	// MaxOutputBytes. If empty, OutputTruncationError is used.
+	OutputTruncation OutputTruncation
+
+	// Progress, if set, is called with batches of lines written to the
+	// standard output and standard error streams while the command runs.
+	// Sensitive values are redacted from each batch.
+	Progress func(message string)
+
+	// ProgressFlushInterval is how often batched lines are passed to
+	// Progress. If zero, DefaultProgressFlushInterval is used.
+	ProgressFlushInterval time.Duration
+
+	// ProgressPrefix is prepended to every line passed to Progress.
+	ProgressPrefix string
+
+	// Timeout, if greater than zero, limits how long the command may run
+	// before it is terminated.
+	Timeout time.Duration
+
+	// TerminationGracePeriod is how long the command is given to exit after
+	// being sent SIGTERM, before it is sent SIGKILL. If zero,
+	// DefaultTerminationGracePeriod is used.
+	TerminationGracePeriod time.Duration
+}
+
+// InheritEnvironment is the mode controlling which variables of the
+// environment of the Terraform process are passed to an executable.
+type InheritEnvironment string
+
+const (
+	// InheritEnvironmentAll passes every variable.
+	InheritEnvironmentAll InheritEnvironment = "all"
+
+	// InheritEnvironmentNone passes no variables, so the executable only
+	// receives the variables explicitly set in its Environment.
+	InheritEnvironmentNone InheritEnvironment = "none"
+
+	// InheritEnvironmentAllowlist passes only the variables named in
+	// InheritedEnvironmentVariables.
+	InheritEnvironmentAllowlist InheritEnvironment = "allowlist"
+)
+
+// InheritEnvironmentModes returns the supported InheritEnvironment modes, for
+// use in schema validation and documentation.
+func InheritEnvironmentModes() []string {
+	return []string{
+		string(InheritEnvironmentAll),
+		string(InheritEnvironmentNone),
+		string(InheritEnvironmentAllowlist),
+	}
+}
+
+// DefaultTerminationGracePeriod is the grace period used when a Command does
+// not set TerminationGracePeriod.
+const DefaultTerminationGracePeriod = 10 * time.Second
+
+// Termination describes how a command was stopped before it exited by itself.
+type Termination string
+
+const (
+	// TerminationNone means the command exited by itself.
+	TerminationNone Termination = ""
+
+	// TerminationTerminated means the command exited after being sent SIGTERM.
+	TerminationTerminated Termination = "SIGTERM"
+
+	// TerminationKilled means the command was sent SIGKILL, either because it
+	// did not exit within the grace period after SIGTERM, or because the
+	// platform does not support graceful termination.
+	TerminationKilled Termination = "SIGKILL"
+)
+
+// Result holds the outcome of running a Command.
+type Result struct {
+	// Started reports whether the executable was started. ExitCode is only
+	// meaningful if it was.
+	Started bool
+
+	// ExitCode is the exit code of the executable.
+	ExitCode int
+
+	// Stdout and Stderr hold everything the executable wrote to its
+	// standard output and standard error streams.
+	Stdout []byte
+	Stderr []byte
+
+	// StdoutDecoded holds Stdout decoded according to the OutputFormat of the
+	// Command. It is null if no format was set, Stdout is empty or the
+	// command failed.
+	StdoutDecoded types.Dynamic
+
+	// StdoutTruncated and StderrTruncated report whether output was
+	// discarded from the respective stream because of MaxOutputBytes.
+	StdoutTruncated bool
+	StderrTruncated bool
+
+	// OutputLimitExceeded reports whether the command was stopped because
+	// it exceeded MaxOutputBytes with OutputTruncationError.
+	OutputLimitExceeded bool
+
+	// TimedOut reports whether the command exceeded its timeout.
+	TimedOut bool
+
+	// Termination describes how the command was stopped, if it was stopped
+	// because of its output limit, its timeout or because Terraform cancelled
+	// the operation.
+	Termination Termination
+}
+
+// Run executes the command and waits for it to exit, retrying it according
+// to Retry. The returned Result is never nil and holds the outcome of the last
+// attempt, so any output can be saved even when diagnostics are returned.
+func (c *Command) Run(ctx context.Context) (*Result, diag.Diagnostics) {
+	if c.Script != "" {
+		return c.runScript(ctx)
+	}
+
+	// Any output or command line containing sensitive values is masked
+	// before it reaches the logs.
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, c.sensitiveValues()...)
+	ctx = tflog.MaskMessageStrings(ctx, c.sensitiveValues()...)
+
+	var attempts []string
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+
+		result, cmd, diags := c.runAttempt(ctx)
+		if diags.HasError() {
+			return result, diags
+		}
+
+		// The command was successfully started and then exited by itself, or
+		// was killed because of a limit.
+		f := c.Limits.stopped(c.Kind, cmd.ProcessState)
+		if f == nil {
+			f = c.checkSuccess(result)
+			if f == nil {
+				diags.Append(c.decode(ctx, result)...)
+				return result, diags
+			}
+		}
+
+		attempts = append(attempts, fmt.Sprintf("Attempt %d: %s after %s", attempt, cmd.ProcessState, time.Since(start).Round(time.Millisecond)))
+
+		if !c.Retry.retries(attempt, result) {
+			diags.Append(c.failureDiagnostic(cmd, result, f, attempts))
+			return result, diags
+		}
+
+		delay := c.Retry.delay(attempt)
+
+		// The output of the failed attempt is discarded, rather than mixed
+		// with the output of the next attempt.
+		if c.Stdout != nil {
+			if err := c.Stdout.Reset(); err != nil {
+				diags.AddAttributeError(
+					c.attributePath("command"),
+					"Command Execution Failed",
+					fmt.Sprintf("The %s was unable to discard the standard output of the failed command before retrying it.", c.Kind)+
+						"\n\n"+
+						fmt.Sprintf("Original Error: %s", err),
+				)
+				return result, diags
+			}
+		}
+
+		tflog.Debug(ctx, "Retrying failed local command", map[string]interface{}{
+			"command":      cmd.String(),
+			"attempt":      attempt,
+			"max_attempts": c.Retry.MaxAttempts,
+			"exit_code":    result.ExitCode,
+			"delay":        delay.String(),
+			"stderr":       logOutput(result.Stderr),
+		})
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			diags.Append(c.failureDiagnostic(cmd, result, f, attempts))
+			return result, diags
+		}
+	}
+}
+
+// runAttempt executes the command once and waits for it to exit. Diagnostics
+// are only returned if the command could not be started or was stopped, so
+// that the caller can check the outcome of a command which exited by itself.
+func (c *Command) runAttempt(ctx context.Context) (*Result, *exec.Cmd, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	result := newResult()
+
+	// The command runs in its own process group and is stopped by Run itself,
+	// rather than with exec.CommandContext which only kills the direct child
+	// and leaves behind any processes spawned by it, such as from scripts.
+	cmd := exec.Command(c.Name, c.Arguments...)
+	cmd.Dir = c.WorkingDirectory
+	cmd.Env = c.environ()
+	setProcessGroup(cmd)
+
+	if !c.RunAs.IsZero() {
+		credential, err := c.RunAs.credential()
+		if err != nil {
+			diags.AddAttributeError(
+				c.attributePath(c.RunAs.attribute()),
+				"Command Execution Failed",
+				fmt.Sprintf("The %s was unable to run the command as the configured user and group.", c.Kind)+
+					"\n\n"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return result, cmd, diags
+		}
+
+		// The limits are applied by the provider executable, which runs as
+		// the user before executing the command, see Limits.start.
+		if c.Limits != nil {
+			if err := credential.canExecuteSelf(); err != nil {
+				diags.AddAttributeError(
+					c.attributePath("limits"),
+					"Command Execution Failed",
+					fmt.Sprintf("The %s was unable to apply the configured limits, as the user the command runs as cannot execute the provider executable, which applies them before executing the command.", c.Kind)+
+						"\n\n"+
+						fmt.Sprintf("Error: %s", err),
+				)
+				return result, cmd, diags
+			}
+		}
+
+		credential.apply(cmd)
+	}
+
+	if c.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(c.Stdin)
+	}
+
+	// The command is not run at all if its sandbox cannot be set up.
+	rules, err := c.Sandbox.ruleset()
+	if err != nil {
+		diags.AddAttributeError(
+			c.attributePath("sandbox"),
+			"Sandbox Setup Failed",
+			fmt.Sprintf("The %s was unable to set up the sandbox configured for the command, so the command was not run.", c.Kind)+
+				"\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return result, cmd, diags
+	}
+	defer rules.close()
+
+	truncation := c.OutputTruncation
+	if truncation == "" {
+		truncation = OutputTruncationError
+	}
+
+	exceeded := make(chan struct{})
+	exceededOnce := &sync.Once{}
+
+	stdout := &outputBuffer{limit: c.MaxOutputBytes, truncation: truncation, exceeded: exceeded, exceededOnce: exceededOnce}
+	stderr := &outputBuffer{limit: c.MaxOutputBytes, truncation: truncation, exceeded: exceeded, exceededOnce: exceededOnce}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	var progress *progressReporter
+	var stdoutProgress, stderrProgress *progressWriter
+	if c.Progress != nil {
+		progress = newProgressReporter(c)
+		stdoutProgress = progress.writer()
+		stderrProgress = progress.writer()
+		cmd.Stdout = io.MultiWriter(stdout, stdoutProgress)
+		cmd.Stderr = io.MultiWriter(stderr, stderrProgress)
+	}
+
+	if c.Stdout != nil {
+		cmd.Stdout = c.Stdout
+	}
+
+	tflog.Trace(ctx, "Executing local command", map[string]interface{}{"command": cmd.String()})
+
+	if progress != nil {
+		progress.start()
+	}
+
+	commandErr := c.run(ctx, cmd, rules, result, exceeded)
+
+	if progress != nil {
+		progress.close(stdoutProgress, stderrProgress)
+	}
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+	result.StdoutTruncated = stdout.Truncated()
+	result.StderrTruncated = stderr.Truncated()
+
+	// The command may have exited by itself before it could be stopped.
+	if truncation == OutputTruncationError && (result.StdoutTruncated || result.StderrTruncated) {
+		result.OutputLimitExceeded = true
+	}
+
+	// ProcessState will always be populated if the command has been successfully started (regardless of exit code)
+	if cmd.ProcessState != nil {
+		result.Started = true
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	tflog.Trace(ctx, "Executed local command", map[string]interface{}{"command": cmd.String(), "stdout": logOutput(result.Stdout), "stderr": logOutput(result.Stderr)})
+
+	if result.OutputLimitExceeded {
+		diags.AddAttributeError(
+			c.attributePath("max_output_bytes"),
+			"Command Output Too Large",
+			c.terminationDetail(result)+
+				"\n\n"+
+				fmt.Sprintf("Command: %s\n", c.Redact(cmd.String()))+
+				fmt.Sprintf("Command Error: %s", c.Redact(string(result.Stderr))),
+		)
+		return result, cmd, diags
+	}
+
+	if result.Termination != TerminationNone {
+		summary := "Command Cancelled"
+		if result.TimedOut {
+			summary = "Command Timed Out"
+		}
+
+		diags.AddAttributeError(
+			c.attributePath("command"),
+			summary,
+			c.terminationDetail(result)+
+				"\n\n"+
+				fmt.Sprintf("Command: %s\n", c.Redact(cmd.String()))+
+				fmt.Sprintf("Command Error: %s", c.Redact(string(result.Stderr))),
+		)
+		return result, cmd, diags
+	}
+
+	var exitError *exec.ExitError
+	if commandErr != nil && !errors.As(commandErr, &exitError) {
+		// The command wasn't successfully started, so there is no exit code.
+		diags.AddAttributeError(
+			c.attributePath("command"),
+			"Command Execution Failed",
+			fmt.Sprintf("The %s received an unexpected error while attempting to execute the command.", c.Kind)+
+				"\n\n"+
+				fmt.Sprintf("Command: %s\n", c.Redact(cmd.String()))+
+				fmt.Sprintf("State: %s", c.Redact(commandErr.Error())),
+		)
+		return result, cmd, diags
+	}
+
+	return result, cmd, diags
+}
+
+// failure describes why a command which exited by itself failed.
+type failure struct {
+	// attribute is the name of the attribute the failure is reported on.
+	attribute string
+
+	// detail explains the failure.
+	detail string
+}
+
+// checkSuccess returns why the command, which exited by itself, failed
+// according to its output regular expressions and exit codes, or nil if it
+// succeeded.
+func (c *Command) checkSuccess(result *Result) *failure {
+	if c.FailureStdoutRegex != nil && c.FailureStdoutRegex.Match(result.Stdout) {
+		return &failure{"failure_stdout_regex", fmt.Sprintf("The %s executed the command but its standard output matched \"failure_stdout_regex\".", c.Kind)}
+	}
+
+	if c.FailureStderrRegex != nil && c.FailureStderrRegex.Match(result.Stderr) {
+		return &failure{"failure_stderr_regex", fmt.Sprintf("The %s executed the command but its standard error matched \"failure_stderr_regex\".", c.Kind)}
+	}
+
+	if c.SuccessStdoutRegex != nil && c.SuccessStdoutRegex.Match(result.Stdout) {
+		return nil
+	}
+
+	if c.SuccessStderrRegex != nil && c.SuccessStderrRegex.Match(result.Stderr) {
+		return nil
+	}
+
+	// A non-zero exit code which the configuration has indicated it will
+	// handle.
+	if c.AllowNonZeroExitCode {
+		return nil
+	}
+
+	if len(c.SuccessExitCodes) > 0 {
+		if containsExitCode(c.SuccessExitCodes, result.ExitCode) {
+			return nil
+		}
+
+		return &failure{"success_exit_codes", fmt.Sprintf("The %s executed the command but received exit code %d, which is not one of the \"success_exit_codes\".", c.Kind, result.ExitCode)}
+	}
+
+	if result.ExitCode == 0 {
+		return nil
+	}
+
+	detail := fmt.Sprintf("The %s executed the command but received a non-zero exit code.", c.Kind)
+	if (c.Kind == KindDataSource || c.Kind == KindEphemeralResource) && len(c.Path.Steps()) == 0 {
+		detail += " If a non-zero exit code is expected and can be handled in configuration, set \"allow_non_zero_exit_code\" to true."
+	}
+
+	return &failure{"command", detail}
+}
+
+// failureDiagnostic returns the error diagnostic for a command which exited by
+// itself and failed, summarizing every attempt if it was retried.
+func (c *Command) failureDiagnostic(cmd *exec.Cmd, result *Result, f *failure, attempts []string) diag.Diagnostic {
+	detail := f.detail
+
+	if len(attempts) > 1 {
+		detail += fmt.Sprintf(" The command was run %d times and failed every attempt.", len(attempts))
+	}
+
+	detail += "\n\n" +
+		fmt.Sprintf("Command: %s\n", c.Redact(cmd.String())) +
+		fmt.Sprintf("Command Error: %s\n", c.Redact(string(result.Stderr))) +
+		fmt.Sprintf("State: %s", cmd.ProcessState)
+
+	if len(attempts) > 1 {
+		detail += "\n\n" + strings.Join(attempts, "\n")
+	}
+
+	return diag.NewAttributeErrorDiagnostic(c.attributePath(f.attribute), "Command Execution Failed", detail)
+}
+
+// containsExitCode reports whether codes contains the exit code.
+func containsExitCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OutputWriter receives an output stream of a command, see Command.Stdout.
+type OutputWriter interface {
+	io.Writer
+
+	// Reset discards everything written so far, before a failed command is
+	// retried.
+	Reset() error
+}
+
+// newResult returns an empty Result.
+func newResult() *Result {
+	return &Result{
+		StdoutDecoded: types.DynamicNull(),
+	}
+}
+
+// attributePath returns the path of the named attribute of the command.
+func (c *Command) attributePath(name string) path.Path {
+	if name == "command" && c.script {
+		name = "script"
+	}
+
+	return AttributePath(c.Path, name)
+}
+
+// AttributePath returns the path of the named attribute within the block at
+// blockPath, or at the root of the schema if blockPath is empty.
+func AttributePath(blockPath path.Path, name string) path.Path {
+	if blockPath.Equal(path.Empty()) {
+		return path.Root(name)
+	}
+
+	return blockPath.AtName(name)
+}
+
+// decode sets StdoutDecoded of the result according to the OutputFormat.
+func (c *Command) decode(ctx context.Context, result *Result) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.OutputFormat == "" || len(result.Stdout) == 0 {
+		return diags
+	}
+
+	decoded, err := Decode(ctx, c.OutputFormat, result.Stdout)
+	if err != nil {
+		diags.AddAttributeError(
+			c.attributePath("output_format"),
+			"Invalid Command Output",
+			fmt.Sprintf("The %s executed the command but was unable to decode its standard output as %s.", c.Kind, c.OutputFormat)+
+				"\n\n"+
+				fmt.Sprintf("Decode Error: %s", c.Redact(err.Error())),
+		)
+		return diags
+	}
+
+	result.StdoutDecoded = decoded
+
+	return diags
+}
+
+// run starts the command, with its limits and in the sandbox enforced by rules
+// if not nil, and waits for it to exit, stopping its process group if it
+// exceeds its output limit, the timeout expires or the context is cancelled
+// first.
+func (c *Command) run(ctx context.Context, cmd *exec.Cmd, rules *ruleset, result *Result, exceeded <-chan struct{}) error {
+	if err := c.Limits.start(cmd, rules); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-exceeded:
+		result.OutputLimitExceeded = true
+	case <-timeout:
+		result.TimedOut = true
+	case <-ctx.Done():
+	}
+
+	gracePeriod := c.TerminationGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultTerminationGracePeriod
+	}
+
+	if terminateProcessGroup(cmd) {
+		tflog.Debug(ctx, "Sent SIGTERM to local command process group", map[string]interface{}{"command": cmd.String(), "grace_period": gracePeriod.String()})
+
+		grace := time.NewTimer(gracePeriod)
+		defer grace.Stop()
+
+		select {
+		case err := <-done:
+			result.Termination = TerminationTerminated
+			return err
+		case <-grace.C:
+		}
+	}
+
+	tflog.Debug(ctx, "Sent SIGKILL to local command process group", map[string]interface{}{"command": cmd.String()})
+
+	killProcessGroup(cmd)
+	result.Termination = TerminationKilled
+
+	return <-done
+}
+
+// terminationDetail describes why and how a stopped command was stopped.
+func (c *Command) terminationDetail(result *Result) string {
+	var reason string
+
+	switch {
+	case result.OutputLimitExceeded:
+		reason = fmt.Sprintf("The %s stopped the command because it wrote more than the \"max_output_bytes\" limit of %d bytes to its standard output or standard error. "+
+			"Increase the limit, or set \"output_truncation\" to discard the excess output instead.", c.Kind, c.MaxOutputBytes)
+	case result.TimedOut:
+		reason = fmt.Sprintf("The %s cancelled the command because it did not complete within the timeout of %s.", c.Kind, c.Timeout)
+	default:
+		reason = fmt.Sprintf("The %s cancelled the command because Terraform cancelled the operation.", c.Kind)
+	}
+
+	gracePeriod := c.TerminationGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultTerminationGracePeriod
+	}
+
+	switch result.Termination {
+	case TerminationNone:
+		return reason + " The command exited before it could be stopped."
+	case TerminationTerminated:
+		return reason + " The process group of the command was sent SIGTERM and exited within the grace period."
+	default:
+		if runtime.GOOS == "windows" {
+			return reason + " The command was forcibly stopped."
+		}
+
+		return reason + fmt.Sprintf(" The process group of the command was sent SIGTERM but did not exit within the grace period of %s, so it was sent SIGKILL.", gracePeriod)
+	}
+}
+
+// RedactedValue replaces sensitive values in diagnostics and logs.
+const RedactedValue = "***"
+
+// Redact replaces every value of SensitiveEnvironment within s with
+// RedactedValue, so that s can be safely displayed.
+func (c *Command) Redact(s string) string {
+	values := c.sensitiveValues()
+	if len(values) == 0 {
+		return s
+	}
+
+	oldnew := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		oldnew = append(oldnew, value, RedactedValue)
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(s)
+}
+
+// sensitiveValues returns the non-empty values of SensitiveEnvironment,
+// longest first so that a value containing another is redacted in full.
+func (c *Command) sensitiveValues() []string {
+	values := make([]string, 0, len(c.SensitiveEnvironment))
+	for _, value := range c.SensitiveEnvironment {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+
+		return values[i] < values[j]
+	})
+
+	return values
+}
+
+// environ returns the environment of the executable, or nil if it should
+// inherit the environment of the Terraform process unchanged.
+func (c *Command) environ() []string {
+	mode := c.InheritEnvironment
+	if mode == "" {
+		mode = InheritEnvironmentAll
+	}
+
+	if mode == InheritEnvironmentAll && c.Environment == nil && c.SensitiveEnvironment == nil {
+		return nil
+	}
+
+	env := make(map[string]string)
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		if c.inherits(mode, key) {
+			env[key] = value
+		}
+	}
+
+	for key, value := range c.Environment {
+		env[key] = value
+	}
+
+	for key, value := range c.SensitiveEnvironment {
+		env[key] = value
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	environ := make([]string, 0, len(keys))
+	for _, key := range keys {
+		environ = append(environ, key+"="+env[key])
+	}
+
+	return environ
+}
+
+// inherits reports whether the named variable of the environment of the
+// Terraform process is passed to the executable.
+func (c *Command) inherits(mode InheritEnvironment, key string) bool {
+	switch mode {
+	case InheritEnvironmentNone:
+		return false
+	case InheritEnvironmentAllowlist:
+		for _, name := range c.InheritedEnvironmentVariables {
+			// Environment variable names are case-insensitive on Windows.
+			if name == key || (runtime.GOOS == "windows" && strings.EqualFold(name, key)) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,663 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCommandRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	t.Setenv("LOCALCOMMAND_INHERITED", "inherited")
+	t.Setenv("HOME", "/home/localcommand")
+
+	testCases := map[string]struct {
+		command       Command
+		expected      *Result
+		expectedError string
+	}{
+		"stdout-stderr": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "echo out; echo err >&2"},
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("out\n"),
+				Stderr:  []byte("err\n"),
+			},
+		},
+		"stdin": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "cat"},
+				Stdin:     []byte("hello"),
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("hello"),
+			},
+		},
+		"environment": {
+			command: Command{
+				Kind:        KindAction,
+				Name:        "sh",
+				Arguments:   []string{"-c", "echo $LOCALCOMMAND_INHERITED $LOCALCOMMAND_SET"},
+				Environment: map[string]string{"LOCALCOMMAND_SET": "set"},
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("inherited set\n"),
+			},
+		},
+		"environment-override": {
+			command: Command{
+				Kind:        KindAction,
+				Name:        "sh",
+				Arguments:   []string{"-c", "echo $LOCALCOMMAND_INHERITED"},
+				Environment: map[string]string{"LOCALCOMMAND_INHERITED": "overridden"},
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("overridden\n"),
+			},
+		},
+		"inherit-environment-none": {
+			command: Command{
+				Kind:               KindDataSource,
+				Name:               "/bin/sh",
+				Arguments:          []string{"-c", "echo \"$LOCALCOMMAND_INHERITED|$LOCALCOMMAND_SET|$HOME\""},
+				Environment:        map[string]string{"LOCALCOMMAND_SET": "set"},
+				InheritEnvironment: InheritEnvironmentNone,
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("|set|\n"),
+			},
+		},
+		"inherit-environment-allowlist": {
+			command: Command{
+				Kind:                          KindEphemeralResource,
+				Name:                          "/bin/sh",
+				Arguments:                     []string{"-c", "echo \"$LOCALCOMMAND_INHERITED|$HOME\""},
+				InheritEnvironment:            InheritEnvironmentAllowlist,
+				InheritedEnvironmentVariables: []string{"LOCALCOMMAND_INHERITED"},
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("inherited|\n"),
+			},
+		},
+		"output-format": {
+			command: Command{
+				Kind:         KindDataSource,
+				Name:         "sh",
+				Arguments:    []string{"-c", "echo '\"decoded\"'"},
+				OutputFormat: OutputFormatJSON,
+			},
+			expected: &Result{
+				Started:       true,
+				Stdout:        []byte("\"decoded\"\n"),
+				StdoutDecoded: types.DynamicValue(types.StringValue("decoded")),
+			},
+		},
+		"output-format-invalid": {
+			command: Command{
+				Kind:         KindEphemeralResource,
+				Name:         "sh",
+				Arguments:    []string{"-c", "echo not-json"},
+				OutputFormat: OutputFormatJSON,
+			},
+			expected: &Result{
+				Started: true,
+				Stdout:  []byte("not-json\n"),
+			},
+			expectedError: "The ephemeral resource executed the command but was unable to decode its standard output as json.",
+		},
+		"max-output-bytes-truncate-tail": {
+			command: Command{
+				Kind:             KindDataSource,
+				Name:             "sh",
+				Arguments:        []string{"-c", "echo 0123456789; echo abcdefghij >&2"},
+				MaxOutputBytes:   4,
+				OutputTruncation: OutputTruncationTail,
+			},
+			expected: &Result{
+				Started:         true,
+				Stdout:          []byte("0123"),
+				Stderr:          []byte("abcd"),
+				StdoutTruncated: true,
+				StderrTruncated: true,
+			},
+		},
+		"max-output-bytes-truncate-head": {
+			command: Command{
+				Kind:             KindDataSource,
+				Name:             "sh",
+				Arguments:        []string{"-c", "echo 0123456789"},
+				MaxOutputBytes:   4,
+				OutputTruncation: OutputTruncationHead,
+			},
+			expected: &Result{
+				Started:         true,
+				Stdout:          []byte("789\n"),
+				StdoutTruncated: true,
+			},
+		},
+		"max-output-bytes-error": {
+			command: Command{
+				Kind:           KindDataSource,
+				Name:           "sh",
+				Arguments:      []string{"-c", "echo 0123456789; sleep 30"},
+				MaxOutputBytes: 4,
+			},
+			expected: &Result{
+				Started:             true,
+				ExitCode:            -1,
+				Stdout:              []byte("0123"),
+				StdoutTruncated:     true,
+				OutputLimitExceeded: true,
+				Termination:         TerminationTerminated,
+			},
+			expectedError: "wrote more than the \"max_output_bytes\" limit of 4 bytes",
+		},
+		"non-zero-exit-code": {
+			command: Command{
+				Kind:      KindEphemeralResource,
+				Name:      "sh",
+				Arguments: []string{"-c", "echo failed >&2; exit 3"},
+			},
+			expected: &Result{
+				Started:  true,
+				ExitCode: 3,
+				Stderr:   []byte("failed\n"),
+			},
+			expectedError: "The ephemeral resource executed the command but received a non-zero exit code.",
+		},
+		"non-zero-exit-code-allowed": {
+			command: Command{
+				Kind:                 KindDataSource,
+				Name:                 "sh",
+				Arguments:            []string{"-c", "exit 3"},
+				AllowNonZeroExitCode: true,
+			},
+			expected: &Result{
+				Started:  true,
+				ExitCode: 3,
+			},
+		},
+		"success-exit-codes": {
+			command: Command{
+				Kind:             KindAction,
+				Name:             "sh",
+				Arguments:        []string{"-c", "exit 2"},
+				SuccessExitCodes: []int{0, 2},
+			},
+			expected: &Result{
+				Started:  true,
+				ExitCode: 2,
+			},
+		},
+		"success-exit-codes-unmatched": {
+			command: Command{
+				Kind:             KindAction,
+				Name:             "sh",
+				Arguments:        []string{"-c", "exit 0"},
+				SuccessExitCodes: []int{1},
+			},
+			expected: &Result{
+				Started: true,
+			},
+			expectedError: "The action executed the command but received exit code 0, which is not one of the \"success_exit_codes\".",
+		},
+		"success-stdout-regex": {
+			command: Command{
+				Kind:               KindDataSource,
+				Name:               "sh",
+				Arguments:          []string{"-c", "echo 'no changes'; exit 1"},
+				SuccessStdoutRegex: regexp.MustCompile(`no changes`),
+			},
+			expected: &Result{
+				Started:  true,
+				ExitCode: 1,
+				Stdout:   []byte("no changes\n"),
+			},
+		},
+		"failure-stderr-regex": {
+			command: Command{
+				Kind:               KindEphemeralResource,
+				Name:               "sh",
+				Arguments:          []string{"-c", "echo 'ERROR: failed' >&2"},
+				FailureStderrRegex: regexp.MustCompile(`^ERROR:`),
+				SuccessStderrRegex: regexp.MustCompile(`failed`),
+			},
+			expected: &Result{
+				Started: true,
+				Stderr:  []byte("ERROR: failed\n"),
+			},
+			expectedError: "The ephemeral resource executed the command but its standard error matched \"failure_stderr_regex\".",
+		},
+		"invalid-working-directory": {
+			command: Command{
+				Kind:             KindDataSource,
+				Name:             "sh",
+				WorkingDirectory: "/definitely/not/a/real/directory",
+			},
+			expected:      &Result{},
+			expectedError: "The data source received an unexpected error while attempting to execute the command.",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := testCase.command.Run(context.Background())
+
+			if testCase.expectedError == "" && diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if testCase.expectedError != "" && (!diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError)) {
+				t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+			}
+
+			if diff := cmp.Diff(testCase.expected, got, cmp.Comparer(func(x, y []byte) bool { return string(x) == string(y) })); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestArguments(t *testing.T) {
+	t.Parallel()
+
+	list := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue("first"),
+		types.StringNull(),
+		types.StringValue(""),
+		types.StringValue("second"),
+	})
+
+	expected := []string{"first", "", "second"}
+
+	if diff := cmp.Diff(expected, Arguments(list)); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+
+	if diff := cmp.Diff([]string{}, Arguments(types.ListNull(types.StringType))); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Parallel()
+
+	m := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"SET":  types.StringValue("value"),
+		"NULL": types.StringNull(),
+	})
+
+	if diff := cmp.Diff(map[string]string{"SET": "value"}, Environment(m)); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+
+	if got := Environment(types.MapNull(types.StringType)); got != nil {
+		t.Errorf("expected nil environment, got: %v", got)
+	}
+}
+
+func TestCommandRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell and process group signals")
+	}
+
+	testCases := map[string]struct {
+		command             Command
+		cancel              bool
+		expectedTimedOut    bool
+		expectedTermination Termination
+		expectedSummary     string
+		expectedError       string
+	}{
+		"terminated": {
+			command: Command{
+				Kind:                   KindDataSource,
+				Name:                   "sh",
+				Arguments:              []string{"-c", "sleep 30"},
+				Timeout:                100 * time.Millisecond,
+				TerminationGracePeriod: 5 * time.Second,
+			},
+			expectedTimedOut:    true,
+			expectedTermination: TerminationTerminated,
+			expectedSummary:     "Command Timed Out",
+			expectedError:       "sent SIGTERM and exited within the grace period",
+		},
+		"killed": {
+			command: Command{
+				Kind:                   KindAction,
+				Name:                   "sh",
+				Arguments:              []string{"-c", "trap '' TERM; sleep 30"},
+				Timeout:                100 * time.Millisecond,
+				TerminationGracePeriod: 100 * time.Millisecond,
+			},
+			expectedTimedOut:    true,
+			expectedTermination: TerminationKilled,
+			expectedSummary:     "Command Timed Out",
+			expectedError:       "so it was sent SIGKILL",
+		},
+		// The backgrounded sleep holds the output pipes open, so Run only
+		// returns promptly if the whole process group is stopped.
+		"grandchild": {
+			command: Command{
+				Kind:                   KindEphemeralResource,
+				Name:                   "sh",
+				Arguments:              []string{"-c", "sleep 30 & wait"},
+				Timeout:                100 * time.Millisecond,
+				TerminationGracePeriod: 5 * time.Second,
+			},
+			expectedTimedOut:    true,
+			expectedTermination: TerminationTerminated,
+			expectedSummary:     "Command Timed Out",
+			expectedError:       "did not complete within the timeout of 100ms",
+		},
+		"cancelled": {
+			command: Command{
+				Kind:                   KindDataSource,
+				Name:                   "sh",
+				Arguments:              []string{"-c", "sleep 30"},
+				TerminationGracePeriod: 5 * time.Second,
+			},
+			cancel:              true,
+			expectedTermination: TerminationTerminated,
+			expectedSummary:     "Command Cancelled",
+			expectedError:       "because Terraform cancelled the operation",
+		},
+		"non-zero-exit-code-allowed": {
+			command: Command{
+				Kind:                 KindDataSource,
+				Name:                 "sh",
+				Arguments:            []string{"-c", "sleep 30"},
+				Timeout:              100 * time.Millisecond,
+				AllowNonZeroExitCode: true,
+			},
+			expectedTimedOut:    true,
+			expectedTermination: TerminationTerminated,
+			expectedSummary:     "Command Timed Out",
+			expectedError:       "did not complete within the timeout",
+		},
+		"completed": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "exit 0"},
+				Timeout:   5 * time.Second,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if testCase.cancel {
+				time.AfterFunc(100*time.Millisecond, cancel)
+			}
+
+			start := time.Now()
+			got, diags := testCase.command.Run(ctx)
+
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("expected command to be stopped promptly, took %s", elapsed)
+			}
+
+			if got.TimedOut != testCase.expectedTimedOut {
+				t.Errorf("expected TimedOut %t, got: %t", testCase.expectedTimedOut, got.TimedOut)
+			}
+
+			if got.Termination != testCase.expectedTermination {
+				t.Errorf("expected Termination %q, got: %q", testCase.expectedTermination, got.Termination)
+			}
+
+			if testCase.expectedError == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", diags)
+				}
+
+				return
+			}
+
+			if !diags.HasError() {
+				t.Fatalf("expected error containing %q, got none", testCase.expectedError)
+			}
+
+			if diags[0].Summary() != testCase.expectedSummary {
+				t.Errorf("expected summary %q, got: %q", testCase.expectedSummary, diags[0].Summary())
+			}
+
+			if !strings.Contains(diags[0].Detail(), testCase.expectedError) {
+				t.Errorf("expected error containing %q, got: %s", testCase.expectedError, diags[0].Detail())
+			}
+		})
+	}
+}
+
+func TestValidateInheritEnvironment(t *testing.T) {
+	t.Parallel()
+
+	variables := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("PATH")})
+
+	testCases := map[string]struct {
+		mode          types.String
+		variables     types.List
+		expectedError string
+	}{
+		"null": {
+			mode:      types.StringNull(),
+			variables: types.ListNull(types.StringType),
+		},
+		"allowlist": {
+			mode:      types.StringValue("allowlist"),
+			variables: variables,
+		},
+		"allowlist-missing-variables": {
+			mode:          types.StringValue("allowlist"),
+			variables:     types.ListNull(types.StringType),
+			expectedError: "Missing Attribute Configuration",
+		},
+		"none-with-variables": {
+			mode:          types.StringValue("none"),
+			variables:     variables,
+			expectedError: "Invalid Attribute Combination",
+		},
+		"null-with-variables": {
+			mode:          types.StringNull(),
+			variables:     variables,
+			expectedError: "Invalid Attribute Combination",
+		},
+		"unknown": {
+			mode:      types.StringUnknown(),
+			variables: variables,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			diags := ValidateInheritEnvironment(path.Empty(), testCase.mode, testCase.variables)
+
+			if testCase.expectedError == "" && diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if testCase.expectedError != "" && (!diags.HasError() || diags[0].Summary() != testCase.expectedError) {
+				t.Fatalf("expected error %q, got: %v", testCase.expectedError, diags)
+			}
+		})
+	}
+}
+
+func TestCommandRunSensitiveEnvironment(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	command := Command{
+		Kind:                 KindDataSource,
+		Name:                 "sh",
+		Arguments:            []string{"-c", "echo \"$TOKEN\"; echo \"token $TOKEN rejected\" >&2; exit 1", "s3cr3t"},
+		Environment:          map[string]string{"TOKEN": "overridden"},
+		SensitiveEnvironment: map[string]string{"TOKEN": "s3cr3t"},
+	}
+
+	got, diags := command.Run(context.Background())
+
+	// The command itself receives the sensitive value unredacted.
+	if string(got.Stdout) != "s3cr3t\n" {
+		t.Errorf("expected stdout %q, got: %q", "s3cr3t\n", got.Stdout)
+	}
+
+	if !diags.HasError() {
+		t.Fatal("expected error, got none")
+	}
+
+	detail := diags[0].Detail()
+
+	if strings.Contains(detail, "s3cr3t") {
+		t.Errorf("expected sensitive value to be redacted, got: %s", detail)
+	}
+
+	if !strings.Contains(detail, "Command Error: token *** rejected") {
+		t.Errorf("expected redacted stderr, got: %s", detail)
+	}
+}
+
+func TestCommandRedact(t *testing.T) {
+	t.Parallel()
+
+	command := Command{
+		SensitiveEnvironment: map[string]string{
+			"SHORT": "abc",
+			"LONG":  "abcdef",
+			"EMPTY": "",
+		},
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"none": {
+			input:    "nothing to see",
+			expected: "nothing to see",
+		},
+		"short": {
+			input:    "value abc here",
+			expected: "value *** here",
+		},
+		"long": {
+			input:    "value abcdef here",
+			expected: "value *** here",
+		},
+		"multiple": {
+			input:    "abc abcdef abc",
+			expected: "*** *** ***",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(testCase.expected, command.Redact(testCase.input)); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+
+	if got := (&Command{}).Redact("abc"); got != "abc" {
+		t.Errorf("expected no redaction without sensitive values, got: %s", got)
+	}
+}
+
+func TestStdin(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		stdin         types.String
+		stdinBase64   types.String
+		expected      []byte
+		expectedError string
+	}{
+		"null": {
+			stdin:       types.StringNull(),
+			stdinBase64: types.StringNull(),
+		},
+		"stdin": {
+			stdin:       types.StringValue("hello"),
+			stdinBase64: types.StringNull(),
+			expected:    []byte("hello"),
+		},
+		"stdin-empty": {
+			stdin:       types.StringValue(""),
+			stdinBase64: types.StringNull(),
+			expected:    []byte{},
+		},
+		"stdin-base64": {
+			stdin:       types.StringNull(),
+			stdinBase64: types.StringValue("AP8K"),
+			expected:    []byte{0x00, 0xff, 0x0a},
+		},
+		"stdin-base64-invalid": {
+			stdin:         types.StringNull(),
+			stdinBase64:   types.StringValue("not base64!"),
+			expectedError: "Invalid Base64 Value",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := Stdin(testCase.stdin, testCase.stdinBase64)
+
+			if testCase.expectedError != "" {
+				if !diags.HasError() || diags[0].Summary() != testCase.expectedError {
+					t.Fatalf("expected error %q, got: %v", testCase.expectedError, diags)
+				}
+
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Limits are resource limits applied to the process of a command, and
+// inherited by any processes it starts, so that a misbehaving command cannot
+// exhaust the resources of the machine. Limits are only supported on Linux,
+// where they are applied before the executable is run. Limits are stored in
+// the private data of ephemeral resources, and passed to the process applying
+// them, so they are encoded as JSON.
+type Limits struct {
+	// MaxMemoryBytes is the maximum size of the virtual memory of the
+	// process (RLIMIT_AS). If zero, it is not limited.
+	MaxMemoryBytes int64 `json:"max_memory_bytes,omitempty"`
+
+	// MaxCPUSeconds is the maximum CPU time of the process (RLIMIT_CPU). If
+	// zero, it is not limited.
+	MaxCPUSeconds int64 `json:"max_cpu_seconds,omitempty"`
+
+	// MaxOpenFiles is the maximum number of open file descriptors of the
+	// process (RLIMIT_NOFILE). If zero, it is not limited.
+	MaxOpenFiles int64 `json:"max_open_files,omitempty"`
+
+	// MaxProcesses is the maximum number of processes of the user the
+	// process runs as (RLIMIT_NPROC). If zero, it is not limited.
+	MaxProcesses int64 `json:"max_processes,omitempty"`
+
+	// Nice, if not nil, is the niceness of the process.
+	Nice *int `json:"nice,omitempty"`
+}
+
+// stopped returns the failure of a command which was killed because of the
+// CPU time or memory limit, as determined from how it exited, or nil if it
+// was not. The other limits make system calls fail, which the command reports
+// itself.
+func (l *Limits) stopped(kind Kind, state *os.ProcessState) *failure {
+	if l == nil || state == nil {
+		return nil
+	}
+
+	if l.MaxCPUSeconds > 0 && cpuLimitExceeded(state, l.MaxCPUSeconds) {
+		return &failure{"limits", fmt.Sprintf("The %s executed the command but it was stopped for exceeding the \"max_cpu_seconds\" limit of %d seconds of CPU time.", kind, l.MaxCPUSeconds)}
+	}
+
+	if l.MaxMemoryBytes > 0 {
+		if signal := memoryLimitSignal(state); signal != nil {
+			return &failure{"limits", fmt.Sprintf("The %s executed the command but it was killed by %s, most likely because it reached the \"max_memory_bytes\" limit of %d bytes.", kind, signal, l.MaxMemoryBytes)}
+		}
+	}
+
+	return nil
+}
+
+// ValidateLimits checks that the limits block within the block at blockPath
+// is only configured on platforms where limits are supported.
+func ValidateLimits(blockPath path.Path, limits *Limits) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if limitsSupported || limits == nil {
+		return diags
+	}
+
+	diags.AddAttributeError(
+		AttributePath(blockPath, "limits"),
+		"Unsupported Block",
+		"The \"limits\" block is only supported when Terraform is running on Linux."+
+			"\n\n"+
+			fmt.Sprintf("Platform: %s", runtime.GOOS),
+	)
+
+	return diags
+}
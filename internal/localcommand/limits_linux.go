@@ -0,0 +1,258 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// limitsSupported reports whether limits can be applied to a command on this
+// platform.
+const limitsSupported = true
+
+const (
+	// limitsHelper is the name the provider executable is run with to
+	// execute a command with limits, see runLimitsHelper.
+	limitsHelper = "terraform-provider-local-limits"
+
+	// limitsHelperErrorFD is the file descriptor of the pipe on which the
+	// helper reports why it could not execute the command.
+	limitsHelperErrorFD = 3
+)
+
+func init() {
+	if len(os.Args) > 0 && os.Args[0] == limitsHelper {
+		runLimitsHelper(os.Args[1:])
+	}
+}
+
+// start starts the command with the limits applied, in the sandbox enforced
+// by rules if not nil.
+//
+// The limits of a process apply to all of its threads, and can only be set
+// for another process once it is running, so the command is started through
+// the provider executable instead, which applies the limits to itself, and
+// enforces the ruleset, before executing the command.
+func (l *Limits) start(cmd *exec.Cmd, rules *ruleset) error {
+	if l == nil || cmd.Err != nil {
+		return rules.start(cmd)
+	}
+
+	encoded, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	// The write end of the pipe is closed when the helper executes the
+	// command, or once the helper has reported why it could not.
+	errReader, errWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer errReader.Close()
+
+	extraFiles := []*os.File{errWriter}
+
+	rulesFD := -1
+	if rules != nil {
+		fd, err := unix.FcntlInt(uintptr(rules.fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err != nil {
+			_ = errWriter.Close()
+			return err
+		}
+
+		rulesFile := os.NewFile(uintptr(fd), "landlock-ruleset")
+		defer rulesFile.Close()
+
+		rulesFD = limitsHelperErrorFD + len(extraFiles)
+		extraFiles = append(extraFiles, rulesFile)
+	}
+
+	name, args := cmd.Path, cmd.Args
+
+	cmd.Path = "/proc/self/exe"
+	cmd.Args = append([]string{limitsHelper, string(encoded), strconv.Itoa(rulesFD), name}, args...)
+	cmd.ExtraFiles = extraFiles
+
+	err = cmd.Start()
+
+	// The command is described as configured, rather than as run through
+	// the helper.
+	cmd.Path, cmd.Args, cmd.ExtraFiles = name, args, nil
+	_ = errWriter.Close()
+
+	if err != nil {
+		return err
+	}
+
+	message, err := io.ReadAll(errReader)
+	if err == nil && len(message) > 0 {
+		err = errors.New(string(message))
+	}
+
+	if err != nil {
+		_ = cmd.Wait()
+		return err
+	}
+
+	return nil
+}
+
+// runLimitsHelper executes a command with limits, as started by
+// Limits.start with the arguments: the JSON encoded limits, the file
+// descriptor of the Landlock ruleset or -1, the path of the executable and
+// its arguments. It never returns.
+func runLimitsHelper(args []string) {
+	err := execWithLimits(args)
+
+	_, _ = unix.Write(limitsHelperErrorFD, []byte(err.Error()))
+	os.Exit(127)
+}
+
+// execWithLimits applies the limits to the current process and executes the
+// command, returning only if that fails.
+func execWithLimits(args []string) error {
+	if len(args) < 4 {
+		return errors.New("missing arguments to execute the command with limits")
+	}
+
+	unix.CloseOnExec(limitsHelperErrorFD)
+
+	var limits Limits
+	if err := json.Unmarshal([]byte(args[0]), &limits); err != nil {
+		return fmt.Errorf("unable to decode the limits: %w", err)
+	}
+
+	rulesFD, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("unable to decode the sandbox: %w", err)
+	}
+
+	if err := limits.set(); err != nil {
+		return err
+	}
+
+	if rulesFD >= 0 {
+		unix.CloseOnExec(rulesFD)
+
+		rules := &ruleset{fd: rulesFD}
+		if err := rules.restrictSelf(); err != nil {
+			return err
+		}
+	}
+
+	if err := syscall.Exec(args[2], args[3:], os.Environ()); err != nil {
+		return fmt.Errorf("unable to execute %s: %w", args[2], err)
+	}
+
+	return nil
+}
+
+// set applies the limits to the current process. The Go runtime keeps the
+// main thread locked during initialization, so the niceness, which Linux
+// sets per thread, applies to the thread which then executes the command.
+func (l *Limits) set() error {
+	resources := []struct {
+		name     string
+		resource int
+		value    int64
+	}{
+		{name: "max_memory_bytes", resource: unix.RLIMIT_AS, value: l.MaxMemoryBytes},
+		{name: "max_cpu_seconds", resource: unix.RLIMIT_CPU, value: l.MaxCPUSeconds},
+		{name: "max_open_files", resource: unix.RLIMIT_NOFILE, value: l.MaxOpenFiles},
+		{name: "max_processes", resource: unix.RLIMIT_NPROC, value: l.MaxProcesses},
+	}
+
+	if l.Nice != nil {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, *l.Nice); err != nil {
+			return fmt.Errorf("unable to set the nice value: %w", err)
+		}
+	}
+
+	for _, r := range resources {
+		if r.value <= 0 {
+			continue
+		}
+
+		var current syscall.Rlimit
+		if err := syscall.Getrlimit(r.resource, &current); err != nil {
+			return fmt.Errorf("unable to get the current %s limit: %w", r.name, err)
+		}
+
+		// Raising the hard limit requires privileges, so it is only ever
+		// lowered, and a higher value is reported rather than ignored.
+		if uint64(r.value) > current.Max {
+			return fmt.Errorf("the %s limit of %d exceeds the current hard limit of %d, which cannot be raised", r.name, r.value, current.Max)
+		}
+
+		limit := syscall.Rlimit{
+			Cur: uint64(r.value),
+			Max: uint64(r.value),
+		}
+
+		// The process is sent SIGXCPU at the soft limit, which reports the
+		// reason it was stopped, rather than SIGKILL at the hard limit, as
+		// long as the current hard limit is higher.
+		if r.resource == unix.RLIMIT_CPU && limit.Max < current.Max {
+			limit.Max++
+		}
+
+		// Unlike unix.Setrlimit, this also stops syscall.Exec from restoring
+		// the limit of open files the Go runtime raised on startup.
+		if err := syscall.Setrlimit(r.resource, &limit); err != nil {
+			return fmt.Errorf("unable to set the %s limit: %w", r.name, err)
+		}
+	}
+
+	return nil
+}
+
+// cpuLimitExceeded reports whether the process was stopped for exceeding
+// the CPU time limit, either by SIGXCPU at the soft limit, or by SIGKILL at
+// the hard limit if it handled SIGXCPU.
+func cpuLimitExceeded(state *os.ProcessState, seconds int64) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
+
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return true
+	case syscall.SIGKILL:
+		return state.UserTime()+state.SystemTime() >= time.Duration(seconds)*time.Second
+	default:
+		return false
+	}
+}
+
+// memoryLimitSignal returns the signal that killed the process if it is one
+// that a process typically receives when it fails to allocate memory, that
+// is SIGSEGV when its stack cannot grow or SIGABRT or SIGBUS when its runtime
+// gives up, or nil otherwise.
+func memoryLimitSignal(state *os.ProcessState) os.Signal {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+
+	switch status.Signal() {
+	case syscall.SIGSEGV, syscall.SIGABRT, syscall.SIGBUS:
+		return status.Signal()
+	default:
+		return nil
+	}
+}
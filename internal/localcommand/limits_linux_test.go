@@ -0,0 +1,157 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommandRunLimits(t *testing.T) {
+	t.Parallel()
+
+	nice := 5
+
+	testCases := map[string]struct {
+		command        Command
+		expectedStdout string
+		expectedError  string
+	}{
+		"nice": {
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "nice"},
+				Limits:    &Limits{Nice: &nice},
+			},
+			expectedStdout: "5\n",
+		},
+		"max-open-files": {
+			// The limits already apply when the executable starts.
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "ulimit -n"},
+				Limits:    &Limits{MaxOpenFiles: 4},
+			},
+			expectedStdout: "4\n",
+		},
+		"max-open-files-reached": {
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "exec 3</dev/null 4</dev/null 5</dev/null"},
+				Limits:    &Limits{MaxOpenFiles: 4},
+			},
+			expectedError: "received a non-zero exit code",
+		},
+		"max-memory-bytes": {
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "ulimit -v"},
+				Limits:    &Limits{MaxMemoryBytes: 64 << 20},
+			},
+			expectedStdout: "65536\n",
+		},
+		"unlimited-open-files": {
+			// The limit of open files is not changed by the Go runtime of the
+			// process applying the limits.
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "test \"$(ulimit -n)\" = \"$EXPECTED\" && echo done"},
+				Environment: map[string]string{
+					"EXPECTED": testOpenFilesLimit(t),
+				},
+				Limits: &Limits{MaxCPUSeconds: 60},
+			},
+			expectedStdout: "done\n",
+		},
+		"max-open-files-above-hard-limit": {
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "ulimit -n"},
+				Limits:    &Limits{MaxOpenFiles: testHardOpenFilesLimit(t) + 1},
+			},
+			expectedError: "exceeds the current hard limit",
+		},
+		"max-cpu-seconds": {
+			command: Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", "while :; do :; done"},
+				Limits:    &Limits{MaxCPUSeconds: 1},
+			},
+			expectedError: `it was stopped for exceeding the "max_cpu_seconds" limit of 1 seconds of CPU time`,
+		},
+		"max-cpu-seconds-allow-non-zero-exit-code": {
+			command: Command{
+				Kind:                 KindDataSource,
+				Name:                 "sh",
+				Arguments:            []string{"-c", "while :; do :; done"},
+				AllowNonZeroExitCode: true,
+				Limits:               &Limits{MaxCPUSeconds: 1},
+			},
+			expectedError: `"max_cpu_seconds" limit`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, diags := testCase.command.Run(context.Background())
+
+			if testCase.expectedError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+				}
+
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if diff := cmp.Diff(testCase.expectedStdout, string(result.Stdout)); diff != "" {
+				t.Errorf("unexpected stdout difference: %s", diff)
+			}
+		})
+	}
+}
+
+// testOpenFilesLimit returns the limit of open files of commands run without
+// limits.
+func testOpenFilesLimit(t *testing.T) string {
+	t.Helper()
+
+	result, diags := (&Command{Kind: KindAction, Name: "sh", Arguments: []string{"-c", "ulimit -n"}}).Run(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	return strings.TrimSpace(string(result.Stdout))
+}
+
+// testHardOpenFilesLimit returns the hard limit of open files, which the
+// limits cannot exceed.
+func testHardOpenFilesLimit(t *testing.T) int64 {
+	t.Helper()
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Fatalf("unable to get the limit of open files: %s", err)
+	}
+
+	return int64(limit.Max)
+}
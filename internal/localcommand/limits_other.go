@@ -0,0 +1,39 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package localcommand
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// limitsSupported reports whether limits can be applied to a command on this
+// platform.
+const limitsSupported = false
+
+// start returns an error if any limits are configured, as they cannot be
+// applied on this platform, and otherwise starts the command.
+func (l *Limits) start(cmd *exec.Cmd, rules *ruleset) error {
+	if l == nil {
+		return rules.start(cmd)
+	}
+
+	return fmt.Errorf("limits are only supported on Linux, not %s", runtime.GOOS)
+}
+
+// cpuLimitExceeded always reports false, as limits cannot be applied on this
+// platform.
+func cpuLimitExceeded(state *os.ProcessState, seconds int64) bool {
+	return false
+}
+
+// memoryLimitSignal always returns nil, as limits cannot be applied on this
+// platform.
+func memoryLimitSignal(state *os.ProcessState) os.Signal {
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLimitsStopped(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("limits are only supported on Linux")
+	}
+
+	testCases := map[string]struct {
+		limits         *Limits
+		script         string
+		expectedDetail string
+	}{
+		"nil": {
+			script: "kill -XCPU $$",
+		},
+		"cpu-sigxcpu": {
+			limits:         &Limits{MaxCPUSeconds: 1},
+			script:         "kill -XCPU $$",
+			expectedDetail: `The action executed the command but it was stopped for exceeding the "max_cpu_seconds" limit of 1 seconds of CPU time.`,
+		},
+		"cpu-sigkill-below-limit": {
+			// Killed by something else before using its CPU time.
+			limits: &Limits{MaxCPUSeconds: 60},
+			script: "kill -KILL $$",
+		},
+		"memory-sigsegv": {
+			limits:         &Limits{MaxMemoryBytes: 1 << 30},
+			script:         "kill -SEGV $$",
+			expectedDetail: `The action executed the command but it was killed by segmentation fault, most likely because it reached the "max_memory_bytes" limit of 1073741824 bytes.`,
+		},
+		"memory-sigabrt": {
+			limits:         &Limits{MaxMemoryBytes: 1 << 30},
+			script:         "kill -ABRT $$",
+			expectedDetail: `The action executed the command but it was killed by aborted, most likely because it reached the "max_memory_bytes" limit of 1073741824 bytes.`,
+		},
+		"memory-unconfigured": {
+			limits: &Limits{MaxOpenFiles: 8},
+			script: "kill -SEGV $$",
+		},
+		"exited": {
+			// Reaching the other limits makes system calls fail, which
+			// cannot be told apart from any other failure.
+			limits: &Limits{MaxMemoryBytes: 1 << 30, MaxCPUSeconds: 1, MaxOpenFiles: 8, MaxProcesses: 2},
+			script: "exit 1",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := exec.Command("sh", "-c", testCase.script)
+			_ = cmd.Run()
+
+			got := testCase.limits.stopped(KindAction, cmd.ProcessState)
+
+			if testCase.expectedDetail == "" {
+				if got != nil {
+					t.Fatalf("expected no failure, got: %v", got)
+				}
+
+				return
+			}
+
+			if got == nil {
+				t.Fatal("expected failure, got none")
+			}
+
+			if diff := cmp.Diff("limits", got.attribute); diff != "" {
+				t.Errorf("unexpected attribute difference: %s", diff)
+			}
+
+			if diff := cmp.Diff(testCase.expectedDetail, got.detail); diff != "" {
+				t.Errorf("unexpected detail difference: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Lookup verifies that the named executable can be found, either on the PATH
+// or at the given path, returning an error diagnostic on attributePath if it
+// cannot.
+func Lookup(attributePath path.Path, kind Kind, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if _, err := exec.LookPath(name); err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Command Lookup Failed",
+			fmt.Sprintf("The %s received an unexpected error while attempting to find the command.", kind)+
+				"\n\n"+
+				"The command must be accessible according to the platform where Terraform is running."+
+				"\n\n"+
+				"If the expected command should be automatically found on the platform where Terraform is running, "+
+				"ensure that the command is in an expected directory. On Unix-based platforms, these directories are "+
+				"typically searched based on the '$PATH' environment variable. On Windows-based platforms, these directories "+
+				"are typically searched based on the '%PATH%' environment variable."+
+				"\n\n"+
+				"If the expected command is relative to the Terraform configuration, it is recommended that the command name includes "+
+				"the interpolated value of 'path.module' before the command name to ensure that it is compatible with varying module usage. For example: \"${path.module}/my-command\""+
+				"\n\n"+
+				"The command must also be executable according to the platform where Terraform is running. On Unix-based platforms, the file on the filesystem must have the executable bit set. "+
+				"On Windows-based platforms, no action is typically necessary."+
+				"\n\n"+
+				fmt.Sprintf("Platform: %s\n", runtime.GOOS)+
+				fmt.Sprintf("Command: %s\n", name)+
+				fmt.Sprintf("Error: %s", err),
+		)
+	}
+
+	return diags
+}
@@ -0,0 +1,302 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat is the format used to decode the standard output of a command
+// into a Terraform value.
+type OutputFormat string
+
+const (
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatTOML   OutputFormat = "toml"
+	OutputFormatDotenv OutputFormat = "dotenv"
+	OutputFormatRaw    OutputFormat = "raw"
+)
+
+// OutputFormats returns the supported OutputFormat values, for use in schema
+// validation and documentation.
+func OutputFormats() []string {
+	return []string{
+		string(OutputFormatJSON),
+		string(OutputFormatYAML),
+		string(OutputFormatTOML),
+		string(OutputFormatDotenv),
+		string(OutputFormatRaw),
+	}
+}
+
+// Decode decodes data in the given format into a Terraform value. Objects are
+// returned as object values and arrays as tuple values, matching the
+// built-in Terraform decoding functions such as jsondecode.
+func Decode(ctx context.Context, format OutputFormat, data []byte) (types.Dynamic, error) {
+	var decoded interface{}
+
+	switch format {
+	case OutputFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&decoded); err != nil {
+			return types.DynamicNull(), err
+		}
+
+		// Trailing data, such as a second document, is not valid JSON.
+		if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+			return types.DynamicNull(), errors.New("invalid character after top-level value")
+		}
+	case OutputFormatYAML:
+		if err := yaml.Unmarshal(data, &decoded); err != nil {
+			return types.DynamicNull(), err
+		}
+	case OutputFormatTOML:
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return types.DynamicNull(), err
+		}
+
+		decoded = table
+	case OutputFormatDotenv:
+		variables, err := parseDotenv(data)
+		if err != nil {
+			return types.DynamicNull(), err
+		}
+
+		decoded = variables
+	case OutputFormatRaw:
+		return types.DynamicValue(types.StringValue(string(data))), nil
+	default:
+		return types.DynamicNull(), fmt.Errorf("unsupported output format %q", format)
+	}
+
+	value, err := toValue(ctx, decoded)
+	if err != nil {
+		return types.DynamicNull(), err
+	}
+
+	return types.DynamicValue(value), nil
+}
+
+// toValue converts a value decoded by one of the supported formats into the
+// equivalent Terraform value.
+func toValue(ctx context.Context, decoded interface{}) (attr.Value, error) {
+	switch v := decoded.(type) {
+	case nil:
+		return types.DynamicNull(), nil
+	case string:
+		return types.StringValue(v), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NumberValue(f), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int64:
+		return types.NumberValue(new(big.Float).SetInt64(v)), nil
+	case uint64:
+		return types.NumberValue(new(big.Float).SetUint64(v)), nil
+	case float64:
+		// Terraform numbers cannot represent NaN or infinity, which YAML
+		// and TOML both support.
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("unsupported number %v", v)
+		}
+
+		return types.NumberValue(big.NewFloat(v)), nil
+	case time.Time:
+		return types.StringValue(v.Format(time.RFC3339Nano)), nil
+	case []interface{}:
+		return toTuple(ctx, v)
+	case []map[string]interface{}:
+		elements := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			elements = append(elements, element)
+		}
+
+		return toTuple(ctx, elements)
+	case map[string]interface{}:
+		return toObject(ctx, v)
+	case map[string]string:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[key] = value
+		}
+
+		return toObject(ctx, object)
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = value
+		}
+
+		return toObject(ctx, object)
+	default:
+		// Remaining types, such as TOML local dates and times, have a
+		// canonical string representation.
+		if stringer, ok := v.(fmt.Stringer); ok {
+			return types.StringValue(stringer.String()), nil
+		}
+
+		return nil, fmt.Errorf("unsupported value of type %T", decoded)
+	}
+}
+
+func toTuple(ctx context.Context, decoded []interface{}) (attr.Value, error) {
+	elementTypes := make([]attr.Type, 0, len(decoded))
+	elements := make([]attr.Value, 0, len(decoded))
+
+	for _, element := range decoded {
+		value, err := toValue(ctx, element)
+		if err != nil {
+			return nil, err
+		}
+
+		elementTypes = append(elementTypes, value.Type(ctx))
+		elements = append(elements, value)
+	}
+
+	tuple, diags := types.TupleValue(elementTypes, elements)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unable to build tuple value: %v", diags)
+	}
+
+	return tuple, nil
+}
+
+func toObject(ctx context.Context, decoded map[string]interface{}) (attr.Value, error) {
+	attributeTypes := make(map[string]attr.Type, len(decoded))
+	attributes := make(map[string]attr.Value, len(decoded))
+
+	for key, element := range decoded {
+		value, err := toValue(ctx, element)
+		if err != nil {
+			return nil, err
+		}
+
+		attributeTypes[key] = value.Type(ctx)
+		attributes[key] = value
+	}
+
+	object, diags := types.ObjectValue(attributeTypes, attributes)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unable to build object value: %v", diags)
+	}
+
+	return object, nil
+}
+
+// parseDotenv parses KEY=VALUE lines. Blank lines, comments and an optional
+// "export" prefix are supported, as are single-quoted (literal) and
+// double-quoted (escaped) values. Unlike some dotenv implementations, values
+// are never expanded with variables, so the environment of the Terraform
+// process cannot leak into the decoded value.
+func parseDotenv(data []byte) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t\"'") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+
+		value, err := dotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		variables[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
+
+func dotenvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single-quoted value")
+		}
+
+		return value[1 : end+1], nil
+	case strings.HasPrefix(value, `"`):
+		var builder strings.Builder
+
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '"':
+				return builder.String(), nil
+			case '\\':
+				i++
+				if i == len(value) {
+					return "", errors.New("unterminated double-quoted value")
+				}
+
+				switch value[i] {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				case 'r':
+					builder.WriteByte('\r')
+				default:
+					builder.WriteByte(value[i])
+				}
+			default:
+				builder.WriteByte(value[i])
+			}
+		}
+
+		return "", errors.New("unterminated double-quoted value")
+	default:
+		// An unquoted value ends at an inline comment.
+		if index := strings.Index(value, " #"); index >= 0 {
+			value = value[:index]
+		}
+
+		return strings.TrimSpace(value), nil
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	object := func(attributes map[string]attr.Value) types.Object {
+		attributeTypes := make(map[string]attr.Type, len(attributes))
+		for key, value := range attributes {
+			attributeTypes[key] = value.Type(context.Background())
+		}
+
+		return types.ObjectValueMust(attributeTypes, attributes)
+	}
+
+	testCases := map[string]struct {
+		format        OutputFormat
+		data          string
+		expected      attr.Value
+		expectedError string
+	}{
+		"json": {
+			format: OutputFormatJSON,
+			data:   `{"name": "test", "count": 3, "enabled": true, "missing": null, "items": ["a", 1]}`,
+			expected: object(map[string]attr.Value{
+				"name":    types.StringValue("test"),
+				"count":   types.NumberValue(big.NewFloat(3)),
+				"enabled": types.BoolValue(true),
+				"missing": types.DynamicNull(),
+				"items": types.TupleValueMust(
+					[]attr.Type{types.StringType, types.NumberType},
+					[]attr.Value{types.StringValue("a"), types.NumberValue(big.NewFloat(1))},
+				),
+			}),
+		},
+		"json-invalid": {
+			format:        OutputFormatJSON,
+			data:          `{"name": `,
+			expectedError: "unexpected EOF",
+		},
+		"json-trailing-data": {
+			format:        OutputFormatJSON,
+			data:          `{} {}`,
+			expectedError: "invalid character after top-level value",
+		},
+		"yaml": {
+			format: OutputFormatYAML,
+			data:   "name: test\nitems:\n  - a\n  - 1\n",
+			expected: object(map[string]attr.Value{
+				"name": types.StringValue("test"),
+				"items": types.TupleValueMust(
+					[]attr.Type{types.StringType, types.NumberType},
+					[]attr.Value{types.StringValue("a"), types.NumberValue(big.NewFloat(1))},
+				),
+			}),
+		},
+		"yaml-invalid": {
+			format:        OutputFormatYAML,
+			data:          "name: [",
+			expectedError: "did not find expected node content",
+		},
+		"yaml-nan": {
+			format:        OutputFormatYAML,
+			data:          "a: .nan\n",
+			expectedError: "unsupported number NaN",
+		},
+		"yaml-inf": {
+			format:        OutputFormatYAML,
+			data:          "a: [1, -.inf]\n",
+			expectedError: "unsupported number -Inf",
+		},
+		"toml": {
+			format: OutputFormatTOML,
+			data:   "name = \"test\"\n\n[server]\nport = 8080\n",
+			expected: object(map[string]attr.Value{
+				"name": types.StringValue("test"),
+				"server": object(map[string]attr.Value{
+					"port": types.NumberValue(big.NewFloat(8080)),
+				}),
+			}),
+		},
+		"toml-invalid": {
+			format:        OutputFormatTOML,
+			data:          "name = ",
+			expectedError: "expected value",
+		},
+		"toml-nan": {
+			format:        OutputFormatTOML,
+			data:          "a = nan\n",
+			expectedError: "unsupported number NaN",
+		},
+		"toml-inf": {
+			format:        OutputFormatTOML,
+			data:          "[server]\nlimit = +inf\n",
+			expectedError: "unsupported number +Inf",
+		},
+		"dotenv": {
+			format: OutputFormatDotenv,
+			data:   "# comment\n\nexport NAME=test # inline comment\nSINGLE='$HOME literal'\nDOUBLE=\"line\\nbreak\"\nEMPTY=\n",
+			expected: object(map[string]attr.Value{
+				"NAME":   types.StringValue("test"),
+				"SINGLE": types.StringValue("$HOME literal"),
+				"DOUBLE": types.StringValue("line\nbreak"),
+				"EMPTY":  types.StringValue(""),
+			}),
+		},
+		"dotenv-invalid": {
+			format:        OutputFormatDotenv,
+			data:          "NAME=test\nnot a variable\n",
+			expectedError: "line 2: expected KEY=VALUE",
+		},
+		"dotenv-unterminated": {
+			format:        OutputFormatDotenv,
+			data:          "NAME=\"test\n",
+			expectedError: "line 1: unterminated double-quoted value",
+		},
+		"raw": {
+			format:   OutputFormatRaw,
+			data:     "not decoded\n",
+			expected: types.StringValue("not decoded\n"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Decode(context.Background(), testCase.format, []byte(testCase.data))
+
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.Equal(types.DynamicValue(testCase.expected)) {
+				t.Errorf("expected %s, got: %s", testCase.expected, got)
+			}
+		})
+	}
+}
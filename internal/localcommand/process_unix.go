@@ -0,0 +1,36 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package localcommand
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that any
+// processes it spawns can be signalled together with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcessGroup asks the process group of the command to exit by
+// sending it SIGTERM. It reports whether a graceful termination was
+// requested, which is always the case on Unix-based platforms.
+func terminateProcessGroup(cmd *exec.Cmd) bool {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+
+	return true
+}
+
+// killProcessGroup forcibly stops the process group of the command by
+// sending it SIGKILL.
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
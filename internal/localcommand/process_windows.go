@@ -0,0 +1,28 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package localcommand
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows, where there is no equivalent of a
+// Unix process group that can be signalled.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup forcibly stops the command, as Windows has no
+// equivalent of SIGTERM. It reports that no graceful termination was
+// requested.
+func terminateProcessGroup(cmd *exec.Cmd) bool {
+	_ = cmd.Process.Kill()
+
+	return false
+}
+
+// killProcessGroup forcibly stops the command.
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProgressFlushInterval is the flush interval used when a Command sets
+// Progress but not ProgressFlushInterval.
+const DefaultProgressFlushInterval = time.Second
+
+// maxProgressLineBytes is the length at which an incomplete line is reported
+// without waiting for the rest of it.
+const maxProgressLineBytes = 64 * 1024
+
+// progressReporter batches complete lines written to the output streams of a
+// command and reports them through the Progress function of the command
+// every flush interval, so that output is displayed while the command runs
+// without reporting every line individually.
+type progressReporter struct {
+	command *Command
+
+	mu      sync.Mutex
+	pending []string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newProgressReporter(c *Command) *progressReporter {
+	return &progressReporter{
+		command: c,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start begins flushing batched lines every flush interval until close is
+// called.
+func (p *progressReporter) start() {
+	interval := p.command.ProgressFlushInterval
+	if interval <= 0 {
+		interval = DefaultProgressFlushInterval
+	}
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.flush()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// writer returns an io.Writer for a single output stream. Each stream keeps
+// its own incomplete line, so lines from different streams are not mixed.
+func (p *progressReporter) writer() *progressWriter {
+	return &progressWriter{reporter: p}
+}
+
+// close stops flushing and reports any remaining output, including the final
+// incomplete line of each of the given writers.
+func (p *progressReporter) close(writers ...*progressWriter) {
+	close(p.stop)
+	<-p.done
+
+	for _, w := range writers {
+		if w.partial.Len() > 0 {
+			p.add(w.partial.String())
+			w.partial.Reset()
+		}
+	}
+
+	p.flush()
+}
+
+func (p *progressReporter) add(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, p.command.ProgressPrefix+line)
+}
+
+func (p *progressReporter) flush() {
+	p.mu.Lock()
+	lines := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+
+	p.command.Progress(p.command.Redact(strings.Join(lines, "\n")))
+}
+
+type progressWriter struct {
+	reporter *progressReporter
+	partial  bytes.Buffer
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		index := bytes.IndexByte(p, '\n')
+		if index < 0 {
+			w.partial.Write(p)
+
+			// A very long line is reported in parts rather than buffered
+			// until the command writes a newline.
+			if w.partial.Len() >= maxProgressLineBytes {
+				w.reporter.add(w.partial.String())
+				w.partial.Reset()
+			}
+
+			break
+		}
+
+		w.partial.Write(p[:index])
+		w.reporter.add(strings.TrimSuffix(w.partial.String(), "\r"))
+		w.partial.Reset()
+
+		p = p[index+1:]
+	}
+
+	return n, nil
+}
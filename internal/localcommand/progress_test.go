@@ -0,0 +1,98 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommandRunProgress(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	var mu sync.Mutex
+	var messages []string
+
+	command := Command{
+		Kind:                  KindAction,
+		Name:                  "sh",
+		Arguments:             []string{"-c", "echo first; echo \"second $TOKEN\" >&2; sleep 0.5; printf 'third\\r\\nfinal'"},
+		SensitiveEnvironment:  map[string]string{"TOKEN": "s3cr3t"},
+		ProgressFlushInterval: 50 * time.Millisecond,
+		ProgressPrefix:        "> ",
+		Progress: func(message string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			messages = append(messages, message)
+		},
+	}
+
+	result, diags := command.Run(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	// Output is still captured in full alongside the progress.
+	if string(result.Stdout) != "first\nthird\r\nfinal" {
+		t.Errorf("unexpected stdout: %q", result.Stdout)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(messages) < 2 {
+		t.Fatalf("expected output to be reported in multiple batches while running, got: %q", messages)
+	}
+
+	lines := strings.Split(strings.Join(messages, "\n"), "\n")
+
+	// Lines from the two streams may be reported in either order.
+	if lines[0] > lines[1] {
+		lines[0], lines[1] = lines[1], lines[0]
+	}
+
+	expected := []string{"> first", "> second ***", "> third", "> final"}
+
+	if diff := cmp.Diff(expected, lines); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+}
+
+func TestProgressWriterLongLine(t *testing.T) {
+	t.Parallel()
+
+	var messages []string
+
+	reporter := newProgressReporter(&Command{
+		Progress: func(message string) {
+			messages = append(messages, message)
+		},
+	})
+
+	writer := reporter.writer()
+	_, _ = writer.Write([]byte(strings.Repeat("a", maxProgressLineBytes+1)))
+
+	// The incomplete line is reported without waiting for a newline.
+	if len(reporter.pending) != 1 || writer.partial.Len() != 0 {
+		t.Fatalf("expected long line to be pending, got %d pending lines and %d buffered bytes", len(reporter.pending), writer.partial.Len())
+	}
+
+	reporter.start()
+	reporter.close(writer)
+
+	if len(messages) != 1 || len(messages[0]) != maxProgressLineBytes+1 {
+		t.Fatalf("expected long line to be reported, got %d messages", len(messages))
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Protocol is how a command is passed its input and returns its output.
+type Protocol string
+
+const (
+	// ProtocolRaw passes stdin to the command as is and returns its standard
+	// output as is.
+	ProtocolRaw Protocol = "raw"
+
+	// ProtocolJSON passes a Request to the command as a JSON document on its
+	// standard input and expects a Response as a JSON document on its
+	// standard output.
+	ProtocolJSON Protocol = "json"
+)
+
+// Protocols returns the supported Protocol values, for use in schema
+// validation and documentation.
+func Protocols() []string {
+	return []string{
+		string(ProtocolRaw),
+		string(ProtocolJSON),
+	}
+}
+
+// Operation is the operation requested of a command using ProtocolJSON.
+type Operation string
+
+const (
+	OperationPlan   Operation = "plan"
+	OperationCreate Operation = "create"
+	OperationRead   Operation = "read"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// Request is the document passed to a command using ProtocolJSON.
+type Request struct {
+	// Operation is the operation requested of the command.
+	Operation Operation
+
+	// Config is the input from configuration.
+	Config types.Dynamic
+
+	// PriorState is the state returned by the command for the previous
+	// operation, or null if the object does not exist yet.
+	PriorState types.Dynamic
+
+	// PlannedState is the state returned by the command for the plan
+	// operation, or null if it was not planned.
+	PlannedState types.Dynamic
+
+	// Private is the JSON encoded private data returned by the command for
+	// the previous operation, or nil.
+	Private []byte
+}
+
+// Marshal encodes the request as a JSON document. Unknown values cannot be
+// encoded and return an error.
+func (r Request) Marshal(ctx context.Context) ([]byte, error) {
+	config, err := fromValue(ctx, r.Config)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	priorState, err := fromValue(ctx, r.PriorState)
+	if err != nil {
+		return nil, fmt.Errorf("prior_state: %w", err)
+	}
+
+	plannedState, err := fromValue(ctx, r.PlannedState)
+	if err != nil {
+		return nil, fmt.Errorf("planned_state: %w", err)
+	}
+
+	private := json.RawMessage("null")
+	if len(r.Private) > 0 {
+		private = r.Private
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"operation":     r.Operation,
+		"config":        config,
+		"prior_state":   priorState,
+		"planned_state": plannedState,
+		"private":       private,
+	})
+}
+
+// Response is the document returned by a command using ProtocolJSON.
+type Response struct {
+	// State is the new state of the object, or the planned state for the
+	// plan operation. Null indicates that the object does not exist.
+	State types.Dynamic
+
+	// Private is the JSON encoded private data to be passed to the command
+	// for the next operation. If nil, the private data is unchanged.
+	Private []byte
+
+	// RequiresReplace indicates that the object must be replaced rather than
+	// updated, for the plan operation.
+	RequiresReplace bool
+
+	// Diagnostics are the diagnostics returned by the command.
+	Diagnostics diag.Diagnostics
+}
+
+// responseDocument is the JSON document of a Response.
+type responseDocument struct {
+	State           json.RawMessage      `json:"state"`
+	Private         json.RawMessage      `json:"private"`
+	RequiresReplace bool                 `json:"requires_replace"`
+	Diagnostics     []responseDiagnostic `json:"diagnostics"`
+}
+
+type responseDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+}
+
+// ParseResponse decodes a JSON document returned by a command using
+// ProtocolJSON. Empty output is an empty response, so that commands with
+// nothing to return, such as for the delete operation, can omit it.
+func ParseResponse(ctx context.Context, data []byte) (*Response, error) {
+	var document responseDocument
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &Response{State: types.DynamicNull()}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		State:           types.DynamicNull(),
+		RequiresReplace: document.RequiresReplace,
+	}
+
+	if len(document.State) > 0 && !bytes.Equal(document.State, []byte("null")) {
+		state, err := Decode(ctx, OutputFormatJSON, document.State)
+		if err != nil {
+			return nil, fmt.Errorf("state: %w", err)
+		}
+
+		response.State = state
+	}
+
+	if len(document.Private) > 0 {
+		response.Private = document.Private
+	}
+
+	for _, d := range document.Diagnostics {
+		switch d.Severity {
+		case "error":
+			response.Diagnostics.AddError(d.Summary, d.Detail)
+		case "warning":
+			response.Diagnostics.AddWarning(d.Summary, d.Detail)
+		default:
+			return nil, fmt.Errorf("diagnostics: unsupported severity %q, expected \"error\" or \"warning\"", d.Severity)
+		}
+	}
+
+	return response, nil
+}
+
+// fromValue converts a Terraform value into the equivalent value for JSON
+// encoding, in the same way as the built-in jsonencode function.
+func fromValue(ctx context.Context, value attr.Value) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	terraformValue, err := value.ToTerraformValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromTerraformValue(terraformValue)
+}
+
+func fromTerraformValue(value tftypes.Value) (interface{}, error) {
+	if !value.IsKnown() {
+		return nil, errors.New("unknown values cannot be encoded")
+	}
+
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	switch valueType := value.Type(); {
+	case valueType.Is(tftypes.String):
+		var s string
+		err := value.As(&s)
+
+		return s, err
+	case valueType.Is(tftypes.Bool):
+		var b bool
+		err := value.As(&b)
+
+		return b, err
+	case valueType.Is(tftypes.Number):
+		f := new(big.Float)
+		if err := value.As(&f); err != nil {
+			return nil, err
+		}
+
+		return json.Number(f.Text('g', -1)), nil
+	case valueType.Is(tftypes.List{}), valueType.Is(tftypes.Set{}), valueType.Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return nil, err
+		}
+
+		decoded := make([]interface{}, 0, len(elements))
+		for _, element := range elements {
+			v, err := fromTerraformValue(element)
+			if err != nil {
+				return nil, err
+			}
+
+			decoded = append(decoded, v)
+		}
+
+		return decoded, nil
+	case valueType.Is(tftypes.Map{}), valueType.Is(tftypes.Object{}):
+		var elements map[string]tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return nil, err
+		}
+
+		decoded := make(map[string]interface{}, len(elements))
+		for key, element := range elements {
+			v, err := fromTerraformValue(element)
+			if err != nil {
+				return nil, err
+			}
+
+			decoded[key] = v
+		}
+
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %s", valueType)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRequestMarshal(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		request       Request
+		expected      string
+		expectedError string
+	}{
+		"create": {
+			request: Request{
+				Operation: OperationCreate,
+				Config: types.DynamicValue(types.ObjectValueMust(
+					map[string]attr.Type{
+						"name":  types.StringType,
+						"count": types.NumberType,
+						"tags":  types.SetType{ElemType: types.StringType},
+						"items": types.TupleType{ElemTypes: []attr.Type{types.BoolType, types.DynamicType}},
+					},
+					map[string]attr.Value{
+						"name":  types.StringValue("test"),
+						"count": types.NumberValue(big.NewFloat(1.5)),
+						"tags":  types.SetValueMust(types.StringType, []attr.Value{types.StringValue("a")}),
+						"items": types.TupleValueMust(
+							[]attr.Type{types.BoolType, types.DynamicType},
+							[]attr.Value{types.BoolValue(true), types.DynamicNull()},
+						),
+					},
+				)),
+				PriorState:   types.DynamicNull(),
+				PlannedState: types.DynamicNull(),
+			},
+			expected: `{"config":{"count":1.5,"items":[true,null],"name":"test","tags":["a"]},"operation":"create","planned_state":null,"prior_state":null,"private":null}`,
+		},
+		"read": {
+			request: Request{
+				Operation:    OperationRead,
+				Config:       types.DynamicNull(),
+				PriorState:   types.DynamicValue(types.StringValue("id")),
+				PlannedState: types.DynamicNull(),
+				Private:      []byte(`{"token":"abc"}`),
+			},
+			expected: `{"config":null,"operation":"read","planned_state":null,"prior_state":"id","private":{"token":"abc"}}`,
+		},
+		"unknown": {
+			request: Request{
+				Operation: OperationPlan,
+				Config:    types.DynamicValue(types.ListValueMust(types.StringType, []attr.Value{types.StringUnknown()})),
+			},
+			expectedError: "config: unknown values cannot be encoded",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := testCase.request.Marshal(context.Background())
+
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, string(got)); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data                string
+		expectedState       types.Dynamic
+		expectedPrivate     string
+		expectedReplace     bool
+		expectedDiagnostics diag.Diagnostics
+		expectedError       string
+	}{
+		"state": {
+			data: `{"state": {"id": "test"}, "private": {"token": "abc"}}`,
+			expectedState: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{"id": types.StringType},
+				map[string]attr.Value{"id": types.StringValue("test")},
+			)),
+			expectedPrivate: `{"token": "abc"}`,
+		},
+		"empty": {
+			data:          `{}`,
+			expectedState: types.DynamicNull(),
+		},
+		"empty-output": {
+			data:          "\n",
+			expectedState: types.DynamicNull(),
+		},
+		"null-state": {
+			data:          `{"state": null}`,
+			expectedState: types.DynamicNull(),
+		},
+		"plan": {
+			data:            `{"requires_replace": true, "diagnostics": [{"severity": "warning", "summary": "Deprecated", "detail": "Use another name."}]}`,
+			expectedState:   types.DynamicNull(),
+			expectedReplace: true,
+			expectedDiagnostics: diag.Diagnostics{
+				diag.NewWarningDiagnostic("Deprecated", "Use another name."),
+			},
+		},
+		"error": {
+			data:          `{"diagnostics": [{"severity": "error", "summary": "Failed"}]}`,
+			expectedState: types.DynamicNull(),
+			expectedDiagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed", ""),
+			},
+		},
+		"invalid-severity": {
+			data:          `{"diagnostics": [{"severity": "info", "summary": "Failed"}]}`,
+			expectedError: `diagnostics: unsupported severity "info"`,
+		},
+		"unknown-field": {
+			data:          `{"stat": {}}`,
+			expectedError: `unknown field "stat"`,
+		},
+		"invalid": {
+			data:          `not json`,
+			expectedError: "invalid character",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseResponse(context.Background(), []byte(testCase.data))
+
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.State.Equal(testCase.expectedState) {
+				t.Errorf("expected state %s, got: %s", testCase.expectedState, got.State)
+			}
+
+			if diff := cmp.Diff(testCase.expectedPrivate, string(got.Private)); diff != "" {
+				t.Errorf("unexpected private difference: %s", diff)
+			}
+
+			if got.RequiresReplace != testCase.expectedReplace {
+				t.Errorf("expected requires replace %t, got: %t", testCase.expectedReplace, got.RequiresReplace)
+			}
+
+			if diff := cmp.Diff(testCase.expectedDiagnostics, got.Diagnostics); diff != "" {
+				t.Errorf("unexpected diagnostics difference: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	// DefaultRetryInitialDelay is the delay used when a Retry does not set
+	// InitialDelay.
+	DefaultRetryInitialDelay = time.Second
+
+	// DefaultRetryMaxDelay is the delay used when a Retry does not set
+	// MaxDelay.
+	DefaultRetryMaxDelay = 30 * time.Second
+)
+
+// Retry describes how a command which exits by itself and fails is run
+// again. Commands which cannot be started, time out, exceed their output
+// limit or are cancelled are not retried.
+type Retry struct {
+	// MaxAttempts is the maximum number of times the command is run,
+	// including the first attempt.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the second attempt, which is doubled
+	// for every further attempt. If zero, DefaultRetryInitialDelay is used.
+	InitialDelay time.Duration
+
+	// MaxDelay limits the delay between attempts. If zero,
+	// DefaultRetryMaxDelay is used.
+	MaxDelay time.Duration
+
+	// ExitCodes, if not empty, limits retries to failed attempts which
+	// exited with one of the exit codes.
+	ExitCodes []int
+
+	// StderrRegex, if set, limits retries to failed attempts whose standard
+	// error matches the regular expression.
+	StderrRegex *regexp.Regexp
+}
+
+// retries reports whether the failed attempt, with the given result, is
+// followed by another attempt. A nil Retry never retries.
+func (r *Retry) retries(attempt int, result *Result) bool {
+	if r == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+
+	if len(r.ExitCodes) > 0 && !containsExitCode(r.ExitCodes, result.ExitCode) {
+		return false
+	}
+
+	if r.StderrRegex != nil && !r.StderrRegex.Match(result.Stderr) {
+		return false
+	}
+
+	return true
+}
+
+// delay returns the delay after the given failed attempt, doubling from
+// InitialDelay up to MaxDelay.
+func (r *Retry) delay(attempt int) time.Duration {
+	delay := r.InitialDelay
+	if delay <= 0 {
+		delay = DefaultRetryInitialDelay
+	}
+
+	maxDelay := r.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,198 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		retry    Retry
+		attempt  int
+		expected time.Duration
+	}{
+		"default": {
+			attempt:  1,
+			expected: DefaultRetryInitialDelay,
+		},
+		"doubled": {
+			retry:    Retry{InitialDelay: 100 * time.Millisecond},
+			attempt:  3,
+			expected: 400 * time.Millisecond,
+		},
+		"max-delay": {
+			retry:    Retry{InitialDelay: time.Second, MaxDelay: 5 * time.Second},
+			attempt:  4,
+			expected: 5 * time.Second,
+		},
+		"default-max-delay": {
+			attempt:  100,
+			expected: DefaultRetryMaxDelay,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := testCase.retry.delay(testCase.attempt); got != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommandRunRetry(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	// Each attempt appends to the attempts file and fails until it has been
+	// run succeedOn times.
+	script := `echo x >> "$1"; n=$(wc -l < "$1"); echo "attempt $n" >&2; test "$n" -ge "$2" || exit "$3"`
+
+	testCases := map[string]struct {
+		succeedOn        string
+		exitCode         string
+		retry            *Retry
+		expectedExitCode int
+		expectedStderr   string
+		expectedError    string
+	}{
+		"no-retry": {
+			succeedOn:        "2",
+			exitCode:         "1",
+			expectedExitCode: 1,
+			expectedStderr:   "attempt 1\n",
+			expectedError:    "received a non-zero exit code.\n\n",
+		},
+		"succeeds": {
+			succeedOn:      "3",
+			exitCode:       "1",
+			retry:          &Retry{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			expectedStderr: "attempt 3\n",
+		},
+		"exhausted": {
+			succeedOn:        "5",
+			exitCode:         "1",
+			retry:            &Retry{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			expectedExitCode: 1,
+			expectedStderr:   "attempt 3\n",
+			expectedError:    "The command was run 3 times and failed every attempt.",
+		},
+		"exit-codes": {
+			succeedOn:        "3",
+			exitCode:         "2",
+			retry:            &Retry{MaxAttempts: 3, InitialDelay: time.Millisecond, ExitCodes: []int{1}},
+			expectedExitCode: 2,
+			expectedStderr:   "attempt 1\n",
+			expectedError:    "received a non-zero exit code.\n\n",
+		},
+		"stderr-regex": {
+			succeedOn:      "2",
+			exitCode:       "1",
+			retry:          &Retry{MaxAttempts: 3, InitialDelay: time.Millisecond, StderrRegex: regexp.MustCompile(`attempt 1`)},
+			expectedStderr: "attempt 2\n",
+		},
+		"stderr-regex-unmatched": {
+			succeedOn:        "3",
+			exitCode:         "1",
+			retry:            &Retry{MaxAttempts: 3, InitialDelay: time.Millisecond, StderrRegex: regexp.MustCompile(`^attempt 1\n$`)},
+			expectedExitCode: 1,
+			expectedStderr:   "attempt 2\n",
+			expectedError:    "Attempt 2: exit status 1",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := Command{
+				Kind:      KindAction,
+				Name:      "sh",
+				Arguments: []string{"-c", script, "retry", filepath.Join(t.TempDir(), "attempts"), testCase.succeedOn, testCase.exitCode},
+				Retry:     testCase.retry,
+			}
+
+			got, diags := command.Run(context.Background())
+
+			if testCase.expectedError == "" && diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if testCase.expectedError != "" && (!diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError)) {
+				t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+			}
+
+			if got.ExitCode != testCase.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d", testCase.expectedExitCode, got.ExitCode)
+			}
+
+			if string(got.Stderr) != testCase.expectedStderr {
+				t.Errorf("expected stderr %q, got %q", testCase.expectedStderr, got.Stderr)
+			}
+		})
+	}
+}
+
+// resetBuffer is an OutputWriter that records how often it was reset.
+type resetBuffer struct {
+	strings.Builder
+
+	resets int
+}
+
+func (b *resetBuffer) Reset() error {
+	b.Builder.Reset()
+	b.resets++
+
+	return nil
+}
+
+func TestCommandRunRetryStdout(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	stdout := &resetBuffer{}
+	command := Command{
+		Kind:      KindAction,
+		Name:      "sh",
+		Arguments: []string{"-c", `echo x >> "$1"; n=$(wc -l < "$1"); echo "attempt $n"; test "$n" -ge 2`, "sh", filepath.Join(t.TempDir(), "attempts")},
+		Stdout:    stdout,
+		Retry:     &Retry{MaxAttempts: 2, InitialDelay: time.Millisecond},
+	}
+
+	result, diags := command.Run(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	// Only the output of the successful attempt is kept.
+	if got := strings.TrimSpace(stdout.String()); got != "attempt 2" {
+		t.Errorf("expected stdout %q, got: %q", "attempt 2", got)
+	}
+
+	if stdout.resets != 1 {
+		t.Errorf("expected 1 reset, got: %d", stdout.resets)
+	}
+
+	if len(result.Stdout) != 0 {
+		t.Errorf("expected no captured stdout, got: %q", result.Stdout)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localuser"
+)
+
+// RunAs identifies the user and group a command runs as, instead of the user
+// and group of the Terraform process. Running as a different user typically
+// requires Terraform to run as root, and is only supported on Linux.
+type RunAs struct {
+	// User is the name or numeric ID of the user. If empty, the command runs
+	// as the current user.
+	User string
+
+	// Group is the name or numeric ID of the primary group. If empty, the
+	// primary group of User is used, or the current group if User is also
+	// empty.
+	Group string
+}
+
+// IsZero reports whether the command runs as the current user and group.
+func (r RunAs) IsZero() bool {
+	return r.User == "" && r.Group == ""
+}
+
+// attribute returns the name of the attribute that diagnostics about the
+// user and group are reported on.
+func (r RunAs) attribute() string {
+	if r.User == "" {
+		return "run_as_group"
+	}
+
+	return "run_as_user"
+}
+
+// credential identifies the user, primary group and supplementary groups a
+// command runs as.
+type credential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+}
+
+// credential resolves the user and group names or IDs. The supplementary
+// groups are those of the user, so that the command has the same access as
+// when the user runs it.
+func (r RunAs) credential() (*credential, error) {
+	if !runAsSupported {
+		return nil, fmt.Errorf("running a command as a different user or group is only supported on Linux, not %s", runtime.GOOS)
+	}
+
+	result := &credential{
+		uid: uint32(os.Getuid()),
+		gid: uint32(os.Getgid()),
+	}
+
+	var account *user.User
+	if r.User != "" {
+		uid, u, err := localuser.LookupUser(r.User)
+		if err != nil {
+			return nil, err
+		}
+
+		result.uid = uid
+		account = u
+
+		if account == nil && r.Group == "" {
+			return nil, fmt.Errorf("user ID %s has no user account to determine its primary group from, so the group must also be configured", r.User)
+		}
+	}
+
+	if account != nil {
+		gid, err := localuser.ParseID(account.Gid)
+		if err != nil {
+			return nil, fmt.Errorf("user %s has an invalid primary group ID %q", r.User, account.Gid)
+		}
+
+		result.gid = gid
+
+		groupIDs, err := account.GroupIds()
+		if err != nil {
+			return nil, fmt.Errorf("unable to look up the groups of user %s: %w", r.User, err)
+		}
+
+		for _, groupID := range groupIDs {
+			if id, err := localuser.ParseID(groupID); err == nil {
+				result.groups = append(result.groups, id)
+			}
+		}
+	}
+
+	if r.Group != "" {
+		gid, err := localuser.LookupGroup(r.Group)
+		if err != nil {
+			return nil, err
+		}
+
+		result.gid = gid
+	}
+
+	// The supplementary groups of the Terraform process are never passed on.
+	if account == nil {
+		result.groups = []uint32{result.gid}
+	}
+
+	return result, nil
+}
+
+// inGroup reports whether the credential is a member of the group.
+func (c *credential) inGroup(gid uint32) bool {
+	if c.gid == gid {
+		return true
+	}
+
+	for _, group := range c.groups {
+		if group == gid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canExecuteSelf checks whether the credential can execute the provider
+// executable, which is run as the user to apply Limits.
+func (c *credential) canExecuteSelf() error {
+	name, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	return c.canExecute(name)
+}
+
+// chown transfers the ownership of the named files to the user and group the
+// command runs as, so that private files, such as a Script, remain accessible
+// to it.
+func (r RunAs) chown(names ...string) error {
+	if r.IsZero() {
+		return nil
+	}
+
+	credential, err := r.credential()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := os.Chown(name, int(credential.uid), int(credential.gid)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckRunAs verifies that the user and group can be resolved and that the
+// user can execute the named executable, returning an error diagnostic on
+// attributePath, or on the run_as_user or run_as_group attribute next to it,
+// if not.
+func CheckRunAs(attributePath path.Path, kind Kind, name string, runAs RunAs) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if runAs.IsZero() {
+		return diags
+	}
+
+	credential, err := runAs.credential()
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath.ParentPath().AtName(runAs.attribute()),
+			"Invalid Run As Configuration",
+			fmt.Sprintf("The %s is unable to run the command as the configured user and group.", kind)+
+				"\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	if err := credential.canExecute(name); err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Command Not Executable",
+			fmt.Sprintf("The %s is unable to run the command as the configured user and group, as the user cannot execute it.", kind)+
+				"\n\n"+
+				fmt.Sprintf("Command: %s\n", name)+
+				fmt.Sprintf("Error: %s", err),
+		)
+	}
+
+	return diags
+}
+
+// ValidateRunAs checks that run_as_user and run_as_group, for the attributes
+// within the block at blockPath, are only configured on platforms where
+// running a command as a different user is supported.
+func ValidateRunAs(blockPath path.Path, runAsUser types.String, runAsGroup types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if runAsSupported {
+		return diags
+	}
+
+	values := []struct {
+		name  string
+		value types.String
+	}{
+		{name: "run_as_user", value: runAsUser},
+		{name: "run_as_group", value: runAsGroup},
+	}
+
+	for _, v := range values {
+		if v.value.IsNull() {
+			continue
+		}
+
+		diags.AddAttributeError(
+			AttributePath(blockPath, v.name),
+			"Unsupported Attribute",
+			fmt.Sprintf("The %q attribute is only supported when Terraform is running on Linux.", v.name)+
+				"\n\n"+
+				fmt.Sprintf("Platform: %s", runtime.GOOS),
+		)
+	}
+
+	return diags
+}
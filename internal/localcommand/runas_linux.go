@@ -0,0 +1,96 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// runAsSupported reports whether a command can run as a different user or
+// group on this platform.
+const runAsSupported = true
+
+// apply runs the command with the credential.
+func (c *credential) apply(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    c.uid,
+		Gid:    c.gid,
+		Groups: c.groups,
+	}
+}
+
+// canExecute checks the permission bits of the named executable, and of the
+// directories leading to it, to determine whether the credential can execute
+// it.
+func (c *credential) canExecute(name string) error {
+	file, err := exec.LookPath(name)
+	if err != nil {
+		return err
+	}
+
+	file, err = filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if !c.permitted(info) {
+		return fmt.Errorf("user ID %d does not have permission to execute %s", c.uid, file)
+	}
+
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+
+		if !c.permitted(info) {
+			return fmt.Errorf("user ID %d does not have permission to search directory %s", c.uid, dir)
+		}
+
+		if dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+// permitted reports whether the credential has execute permission for a
+// file, or search permission for a directory.
+func (c *credential) permitted(info os.FileInfo) bool {
+	mode := info.Mode().Perm()
+
+	// Root can search any directory, and execute any file with at least one
+	// execute bit set.
+	if c.uid == 0 {
+		return info.IsDir() || mode&0111 != 0
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+
+	switch {
+	case stat.Uid == c.uid:
+		return mode&0100 != 0
+	case c.inGroup(stat.Gid):
+		return mode&0010 != 0
+	default:
+		return mode&0001 != 0
+	}
+}
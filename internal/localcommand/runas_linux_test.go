@@ -0,0 +1,340 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRunAsCredential(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		runAs         RunAs
+		expectedUID   uint32
+		expectedGID   uint32
+		expectedError string
+	}{
+		"user-name": {
+			runAs:       RunAs{User: "root"},
+			expectedUID: 0,
+			expectedGID: 0,
+		},
+		"user-id": {
+			runAs:       RunAs{User: "0"},
+			expectedUID: 0,
+			expectedGID: 0,
+		},
+		"user-and-group-id": {
+			runAs:       RunAs{User: "root", Group: "4321"},
+			expectedUID: 0,
+			expectedGID: 4321,
+		},
+		"unknown-user-id-with-group": {
+			runAs:       RunAs{User: "4321", Group: "4321"},
+			expectedUID: 4321,
+			expectedGID: 4321,
+		},
+		"unknown-user-id": {
+			runAs:         RunAs{User: "4321"},
+			expectedError: "user ID 4321 has no user account",
+		},
+		"unknown-user": {
+			runAs:         RunAs{User: "terraform-provider-local-unknown"},
+			expectedError: "unable to look up user terraform-provider-local-unknown",
+		},
+		"unknown-group": {
+			runAs:         RunAs{Group: "terraform-provider-local-unknown"},
+			expectedError: "unable to look up group terraform-provider-local-unknown",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := testCase.runAs.credential()
+
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got.uid != testCase.expectedUID || got.gid != testCase.expectedGID {
+				t.Errorf("expected %d:%d, got: %d:%d", testCase.expectedUID, testCase.expectedGID, got.uid, got.gid)
+			}
+
+			if !got.inGroup(testCase.expectedGID) {
+				t.Errorf("expected credential to be a member of group %d, got: %v", testCase.expectedGID, got.groups)
+			}
+		})
+	}
+}
+
+// fileInfo is an os.FileInfo with the given permissions and owner.
+type fileInfo struct {
+	os.FileInfo
+
+	mode os.FileMode
+	uid  uint32
+	gid  uint32
+}
+
+func (i fileInfo) Mode() os.FileMode { return i.mode }
+func (i fileInfo) IsDir() bool       { return i.mode.IsDir() }
+func (i fileInfo) Sys() any          { return &syscall.Stat_t{Uid: i.uid, Gid: i.gid} }
+
+func TestCredentialPermitted(t *testing.T) {
+	t.Parallel()
+
+	user := credential{uid: 1000, gid: 1000, groups: []uint32{1000, 2000}}
+
+	testCases := map[string]struct {
+		credential credential
+		info       fileInfo
+		expected   bool
+	}{
+		"owner": {
+			credential: user,
+			info:       fileInfo{mode: 0700, uid: 1000, gid: 0},
+			expected:   true,
+		},
+		"owner-denied": {
+			// The owner permissions apply even if the group or other
+			// permissions would allow it.
+			credential: user,
+			info:       fileInfo{mode: 0611, uid: 1000, gid: 1000},
+			expected:   false,
+		},
+		"primary-group": {
+			credential: user,
+			info:       fileInfo{mode: 0710, uid: 0, gid: 1000},
+			expected:   true,
+		},
+		"supplementary-group": {
+			credential: user,
+			info:       fileInfo{mode: 0710, uid: 0, gid: 2000},
+			expected:   true,
+		},
+		"group-denied": {
+			credential: user,
+			info:       fileInfo{mode: 0701, uid: 0, gid: 2000},
+			expected:   false,
+		},
+		"other": {
+			credential: user,
+			info:       fileInfo{mode: 0701, uid: 0, gid: 0},
+			expected:   true,
+		},
+		"other-denied": {
+			credential: user,
+			info:       fileInfo{mode: 0770, uid: 0, gid: 0},
+			expected:   false,
+		},
+		"root": {
+			credential: credential{uid: 0},
+			info:       fileInfo{mode: 0001, uid: 1000, gid: 1000},
+			expected:   true,
+		},
+		"root-denied": {
+			credential: credential{uid: 0},
+			info:       fileInfo{mode: 0600, uid: 0, gid: 0},
+			expected:   false,
+		},
+		"root-directory": {
+			credential: credential{uid: 0},
+			info:       fileInfo{mode: os.ModeDir | 0700, uid: 1000, gid: 1000},
+			expected:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := testCase.credential.permitted(testCase.info); got != testCase.expected {
+				t.Errorf("expected %t, got: %t", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestCredentialCanExecute(t *testing.T) {
+	t.Parallel()
+
+	// The temporary directory is only searchable by the current user.
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0700); err != nil {
+		t.Fatalf("unable to change directory permissions: %s", err)
+	}
+
+	file := filepath.Join(dir, "executable")
+	if err := os.WriteFile(file, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	if err := (&credential{uid: uint32(os.Getuid()), gid: uint32(os.Getgid())}).canExecute(file); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	err := (&credential{uid: uint32(os.Getuid()) + 1, gid: uint32(os.Getgid()) + 1}).canExecute(file)
+	if err == nil || !strings.Contains(err.Error(), "does not have permission to search directory "+dir) {
+		t.Errorf("expected directory permission error, got: %v", err)
+	}
+}
+
+func TestCommandRunRunAs(t *testing.T) {
+	t.Parallel()
+
+	if os.Getuid() != 0 {
+		t.Skip("running a command as a different user requires root")
+	}
+
+	testCases := map[string]struct {
+		command        Command
+		expectedStdout string
+		expectedError  string
+	}{
+		"command": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "id",
+				Arguments: []string{"-u"},
+				RunAs:     RunAs{User: "4321", Group: "4321"},
+			},
+			expectedStdout: "4321\n",
+		},
+		"group": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "id",
+				Arguments: []string{"-G"},
+				RunAs:     RunAs{Group: "4321"},
+			},
+			expectedStdout: "4321\n",
+		},
+		"script": {
+			command: Command{
+				Kind:   KindDataSource,
+				Script: "id -u",
+				RunAs:  RunAs{User: "4321", Group: "4321"},
+			},
+			expectedStdout: "4321\n",
+		},
+		"unknown-user": {
+			command: Command{
+				Kind:  KindDataSource,
+				Name:  "id",
+				RunAs: RunAs{User: "terraform-provider-local-unknown"},
+			},
+			expectedError: "unable to look up user terraform-provider-local-unknown",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, diags := testCase.command.Run(context.Background())
+
+			if testCase.expectedError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+				}
+
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if diff := cmp.Diff(testCase.expectedStdout, string(result.Stdout)); diff != "" {
+				t.Errorf("unexpected stdout difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCommandRunRunAsLimits(t *testing.T) {
+	t.Parallel()
+
+	if os.Getuid() != 0 {
+		t.Skip("running a command as a different user requires root")
+	}
+
+	command := Command{
+		Kind:      KindDataSource,
+		Name:      "id",
+		Arguments: []string{"-u"},
+		RunAs:     RunAs{User: "4321", Group: "4321"},
+		Limits:    &Limits{MaxOpenFiles: 64},
+	}
+
+	// Whether the user can execute the test executable, which applies the
+	// limits, depends on where it was built.
+	executeErr := (&credential{uid: 4321, gid: 4321}).canExecuteSelf()
+
+	result, diags := command.Run(context.Background())
+
+	if executeErr != nil {
+		if !diags.HasError() || !strings.Contains(diags[0].Detail(), "cannot execute the provider executable") {
+			t.Fatalf("expected provider executable error, got: %v", diags)
+		}
+
+		if diff := cmp.Diff(path.Root("limits"), diags[0].(diag.DiagnosticWithPath).Path()); diff != "" {
+			t.Errorf("unexpected path difference: %s", diff)
+		}
+
+		return
+	}
+
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if diff := cmp.Diff("4321\n", string(result.Stdout)); diff != "" {
+		t.Errorf("unexpected stdout difference: %s", diff)
+	}
+}
+
+func TestCheckRunAs(t *testing.T) {
+	t.Parallel()
+
+	diags := CheckRunAs(path.Root("command"), KindAction, "sh", RunAs{User: "terraform-provider-local-unknown"})
+	if !diags.HasError() {
+		t.Fatal("expected error diagnostic")
+	}
+
+	if diff := cmp.Diff(path.Root("run_as_user"), diags[0].(diag.DiagnosticWithPath).Path()); diff != "" {
+		t.Errorf("unexpected path difference: %s", diff)
+	}
+
+	if diags := CheckRunAs(path.Root("command"), KindAction, "sh", RunAs{}); diags.HasError() {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	if diags := ValidateRunAs(path.Empty(), types.StringValue("root"), types.StringNull()); diags.HasError() {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package localcommand
+
+import (
+	"os/exec"
+)
+
+// runAsSupported reports whether a command can run as a different user or
+// group on this platform.
+const runAsSupported = false
+
+// apply is never called, as credentials cannot be resolved on this platform.
+func (c *credential) apply(cmd *exec.Cmd) {}
+
+// canExecute is never called, as credentials cannot be resolved on this
+// platform.
+func (c *credential) canExecute(name string) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Sandbox restricts the filesystem access of the process of a command, and
+// of any processes it starts, to the configured paths, using the Landlock
+// security module of the Linux kernel. Everything outside the paths is
+// denied, including the executable, its shared libraries and any
+// configuration files it reads, so these must also be covered by the paths.
+// The command is not run if the sandbox cannot be set up, such as when the
+// kernel does not support Landlock.
+//
+// Sandboxed commands run with the no_new_privs flag set, so executables such
+// as sudo cannot gain privileges.
+type Sandbox struct {
+	// ReadOnlyPaths are the files and directories, including everything
+	// beneath them, that the command can read and execute.
+	ReadOnlyPaths []string `json:"read_only_paths,omitempty"`
+
+	// ReadWritePaths are the files and directories, including everything
+	// beneath them, that the command can read, execute, write, create and
+	// remove.
+	ReadWritePaths []string `json:"read_write_paths,omitempty"`
+}
+
+// withReadOnlyPath returns a copy of the sandbox in which the command can also
+// read and execute name, or nil if the sandbox is nil.
+func (s *Sandbox) withReadOnlyPath(name string) *Sandbox {
+	if s == nil {
+		return nil
+	}
+
+	return &Sandbox{
+		ReadOnlyPaths:  append(append([]string{}, s.ReadOnlyPaths...), name),
+		ReadWritePaths: s.ReadWritePaths,
+	}
+}
+
+// ValidateSandbox checks that the sandbox block within the block at blockPath
+// is only configured on platforms where sandboxes are supported.
+func ValidateSandbox(blockPath path.Path, sandbox *Sandbox) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if sandboxSupported || sandbox == nil {
+		return diags
+	}
+
+	diags.AddAttributeError(
+		AttributePath(blockPath, "sandbox"),
+		"Unsupported Block",
+		"The \"sandbox\" block is only supported when Terraform is running on Linux."+
+			"\n\n"+
+			fmt.Sprintf("Platform: %s", runtime.GOOS),
+	)
+
+	return diags
+}
@@ -0,0 +1,225 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxSupported reports whether a command can run in a sandbox on this
+// platform.
+const sandboxSupported = true
+
+// Landlock access rights, see
+// https://docs.kernel.org/userspace-api/landlock.html.
+const (
+	// landlockReadAccess is the access granted to ReadOnlyPaths.
+	landlockReadAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+	// landlockFileAccess is the access that can be granted on a file, rather
+	// than a directory.
+	landlockFileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_TRUNCATE |
+		unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+)
+
+// landlockAccess returns the filesystem access handled by version abi of
+// Landlock, all of which is denied unless granted to a path.
+func landlockAccess(abi int) uint64 {
+	access := uint64(landlockReadAccess |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM)
+
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+
+	if abi >= 5 {
+		access |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	}
+
+	return access
+}
+
+// landlockABI returns the version of Landlock supported by the kernel.
+func landlockABI() (int, error) {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+
+	switch errno {
+	case 0:
+		return int(abi), nil
+	case unix.ENOSYS:
+		return 0, errors.New("the kernel does not support Landlock, which requires Linux 5.13 or later")
+	case unix.EOPNOTSUPP:
+		return 0, errors.New("the kernel supports Landlock but it is disabled, such as by the lsm boot parameter")
+	default:
+		return 0, fmt.Errorf("unable to determine the Landlock version supported by the kernel: %w", errno)
+	}
+}
+
+// ruleset is a Landlock ruleset enforcing a Sandbox.
+type ruleset struct {
+	fd int
+}
+
+// ruleset creates the Landlock ruleset enforcing the sandbox, or returns nil
+// if the sandbox is nil. Every path must exist.
+func (s *Sandbox) ruleset() (*ruleset, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	abi, err := landlockABI()
+	if err != nil {
+		return nil, err
+	}
+
+	handled := landlockAccess(abi)
+
+	attr := unix.LandlockRulesetAttr{
+		Access_fs: handled,
+	}
+
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("unable to create the Landlock ruleset: %w", errno)
+	}
+
+	r := &ruleset{fd: int(fd)}
+
+	rules := []struct {
+		paths  []string
+		access uint64
+	}{
+		{paths: s.ReadOnlyPaths, access: landlockReadAccess},
+		{paths: s.ReadWritePaths, access: handled},
+	}
+
+	for _, rule := range rules {
+		for _, name := range rule.paths {
+			if err := r.allow(name, rule.access&handled); err != nil {
+				r.close()
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// allow grants access to the named file or directory, and everything
+// beneath it.
+func (r *ruleset) allow(name string, access uint64) error {
+	fd, err := unix.Open(name, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open sandbox path %s: %w", name, err)
+	}
+	defer unix.Close(fd)
+
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("unable to stat sandbox path %s: %w", name, err)
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+
+	attr := unix.LandlockPathBeneathAttr{
+		Allowed_access: access,
+		Parent_fd:      int32(fd),
+	}
+
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(r.fd), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&attr)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("unable to add sandbox path %s to the Landlock ruleset: %w", name, errno)
+	}
+
+	return nil
+}
+
+// start starts the command in the sandbox, or without one if r is nil.
+//
+// Landlock restricts the thread that enforces a ruleset and the processes it
+// starts, so the ruleset is enforced on a dedicated thread which starts the
+// command and then exits, leaving the rest of the provider unrestricted.
+func (r *ruleset) start(cmd *exec.Cmd) error {
+	if r == nil {
+		return cmd.Start()
+	}
+
+	// The standard input would otherwise be opened on the restricted thread.
+	if cmd.Stdin == nil {
+		devNull, err := os.Open(os.DevNull)
+		if err != nil {
+			return err
+		}
+		defer devNull.Close()
+
+		cmd.Stdin = devNull
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		// The thread is never unlocked, so that it exits with the goroutine
+		// instead of running other goroutines with the ruleset enforced.
+		runtime.LockOSThread()
+
+		if err := r.restrictSelf(); err != nil {
+			errs <- err
+			return
+		}
+
+		errs <- cmd.Start()
+	}()
+
+	return <-errs
+}
+
+// restrictSelf enforces the ruleset on the calling thread and any processes
+// it starts, which then cannot gain privileges either.
+func (r *ruleset) restrictSelf() error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("unable to set no_new_privs for the sandbox: %w", err)
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(r.fd), 0, 0); errno != 0 {
+		return fmt.Errorf("unable to enforce the Landlock ruleset: %w", errno)
+	}
+
+	return nil
+}
+
+// close releases the ruleset.
+func (r *ruleset) close() {
+	if r != nil {
+		_ = unix.Close(r.fd)
+	}
+}
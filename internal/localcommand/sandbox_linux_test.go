@@ -0,0 +1,182 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localcommand
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testSystemPaths returns the directories holding the executables and
+// shared libraries a sandboxed command needs to run.
+func testSystemPaths(t *testing.T) []string {
+	t.Helper()
+
+	var paths []string
+	for _, name := range []string{"/bin", "/etc", "/lib", "/lib64", "/usr"} {
+		if _, err := os.Stat(name); err == nil {
+			paths = append(paths, name)
+		}
+	}
+
+	return paths
+}
+
+func TestCommandRunSandbox(t *testing.T) {
+	t.Parallel()
+
+	if _, err := landlockABI(); err != nil {
+		t.Skipf("sandboxes are not supported: %s", err)
+	}
+
+	readOnlyDir := t.TempDir()
+	readWriteDir := t.TempDir()
+	deniedDir := t.TempDir()
+
+	for _, dir := range []string{readOnlyDir, readWriteDir, deniedDir} {
+		if err := os.WriteFile(filepath.Join(dir, "file"), []byte("content"), 0600); err != nil {
+			t.Fatalf("unable to write file: %s", err)
+		}
+	}
+
+	sandbox := &Sandbox{
+		ReadOnlyPaths:  append(testSystemPaths(t), readOnlyDir),
+		ReadWritePaths: []string{readWriteDir},
+	}
+
+	testCases := map[string]struct {
+		command        Command
+		expectedStdout string
+		expectedError  string
+	}{
+		"read-only": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "cat",
+				Arguments: []string{filepath.Join(readOnlyDir, "file")},
+				Sandbox:   sandbox,
+			},
+			expectedStdout: "content",
+		},
+		"read-only-write": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "echo changed > " + filepath.Join(readOnlyDir, "file")},
+				Sandbox:   sandbox,
+			},
+			expectedError: "Permission denied",
+		},
+		"read-write": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "echo created > " + filepath.Join(readWriteDir, "created") + " && cat " + filepath.Join(readWriteDir, "created")},
+				Sandbox:   sandbox,
+			},
+			expectedStdout: "created\n",
+		},
+		"denied": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "cat",
+				Arguments: []string{filepath.Join(deniedDir, "file")},
+				Sandbox:   sandbox,
+			},
+			expectedError: "Permission denied",
+		},
+		"script": {
+			command: Command{
+				Kind:    KindDataSource,
+				Script:  "cat " + filepath.Join(readOnlyDir, "file"),
+				Sandbox: sandbox,
+			},
+			expectedStdout: "content",
+		},
+		"executable-denied": {
+			command: Command{
+				Kind:    KindDataSource,
+				Name:    "cat",
+				Sandbox: &Sandbox{ReadOnlyPaths: []string{readOnlyDir}},
+			},
+			expectedError: "permission denied",
+		},
+		"limits": {
+			// The ruleset is enforced by the process applying the limits.
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "sh",
+				Arguments: []string{"-c", "ulimit -n; cat " + filepath.Join(readOnlyDir, "file")},
+				Sandbox:   sandbox,
+				Limits:    &Limits{MaxOpenFiles: 16},
+			},
+			expectedStdout: "16\ncontent",
+		},
+		"limits-denied": {
+			command: Command{
+				Kind:      KindDataSource,
+				Name:      "cat",
+				Arguments: []string{filepath.Join(deniedDir, "file")},
+				Sandbox:   sandbox,
+				Limits:    &Limits{MaxOpenFiles: 16},
+			},
+			expectedError: "Permission denied",
+		},
+		"limits-executable-denied": {
+			command: Command{
+				Kind:    KindDataSource,
+				Name:    "cat",
+				Sandbox: &Sandbox{ReadOnlyPaths: []string{readOnlyDir}},
+				Limits:  &Limits{MaxOpenFiles: 16},
+			},
+			expectedError: "permission denied",
+		},
+		"missing-path": {
+			command: Command{
+				Kind:    KindDataSource,
+				Name:    "cat",
+				Sandbox: &Sandbox{ReadOnlyPaths: []string{filepath.Join(deniedDir, "missing")}},
+			},
+			expectedError: "unable to open sandbox path " + filepath.Join(deniedDir, "missing"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, diags := testCase.command.Run(context.Background())
+
+			if testCase.expectedError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+				}
+
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if diff := cmp.Diff(testCase.expectedStdout, string(result.Stdout)); diff != "" {
+				t.Errorf("unexpected stdout difference: %s", diff)
+			}
+		})
+	}
+
+	// The sandbox only restricts the commands, not the provider.
+	t.Cleanup(func() {
+		if _, err := os.ReadFile(filepath.Join(deniedDir, "file")); err != nil {
+			t.Errorf("unexpected error reading file outside of the sandbox: %s", err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package localcommand
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// sandboxSupported reports whether a command can run in a sandbox on this
+// platform.
+const sandboxSupported = false
+
+// ruleset is never created on this platform.
+type ruleset struct{}
+
+// ruleset returns an error if the sandbox is not nil, as it cannot be
+// enforced on this platform.
+func (s *Sandbox) ruleset() (*ruleset, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("sandboxes are only supported on Linux, not %s", runtime.GOOS)
+}
+
+// start starts the command.
+func (r *ruleset) start(cmd *exec.Cmd) error {
+	return cmd.Start()
+}
+
+// close does nothing.
+func (r *ruleset) close() {}
@@ -0,0 +1,113 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// DefaultInterpreter is the interpreter used when a Command with a Script does
+// not set Interpreter.
+var DefaultInterpreter = []string{"/bin/sh", "-c"}
+
+// shellScriptCommand is the command string passed to an interpreter ending
+// with -c, which reads and runs the script file named by $0 with the remaining
+// arguments. The file is not executed itself, so scripts still run when the
+// temporary directory is on a file system mounted with noexec.
+const shellScriptCommand = `. "$0"`
+
+// scriptFileName is the name of the file a Script is written to, within a
+// private temporary directory.
+const scriptFileName = "script"
+
+// Executable returns the executable run by the command and the path of the
+// attribute configuring it, which is the first element of the interpreter
+// for a Script.
+func (c *Command) Executable() (string, path.Path) {
+	if c.Script == "" {
+		return c.Name, c.attributePath("command")
+	}
+
+	return c.interpreter()[0], c.attributePath("interpreter")
+}
+
+// interpreter returns the Interpreter, or DefaultInterpreter if it is empty.
+func (c *Command) interpreter() []string {
+	if len(c.Interpreter) == 0 {
+		return DefaultInterpreter
+	}
+
+	return c.Interpreter
+}
+
+// scriptArguments returns the arguments passed to the interpreter to run the
+// script file at name. The path of the file follows the interpreter, unless it
+// ends with -c like a shell, which is instead passed a command string running
+// the file, so that the Arguments still reach the script.
+func (c *Command) scriptArguments(name string) []string {
+	interpreter := c.interpreter()
+
+	arguments := append([]string{}, interpreter[1:]...)
+	if interpreter[len(interpreter)-1] == "-c" {
+		arguments = append(arguments, shellScriptCommand)
+	}
+
+	return append(append(arguments, name), c.Arguments...)
+}
+
+// runScript writes the Script to a private executable temporary file, runs
+// the interpreter with the arguments returned by scriptArguments and removes
+// the file once the command has exited.
+func (c *Command) runScript(ctx context.Context) (*Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	dir, err := os.MkdirTemp("", "terraform-provider-local-")
+	if err == nil {
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				tflog.Warn(ctx, "Unable to remove local command script", map[string]interface{}{"path": dir, "error": err.Error()})
+			}
+		}()
+
+		// The directory is only accessible by the current user, so the
+		// script cannot be read or replaced before it is run.
+		err = os.WriteFile(filepath.Join(dir, scriptFileName), []byte(c.Script), 0700)
+
+		// Or by the user the command runs as, instead.
+		if err == nil {
+			err = c.RunAs.chown(dir, filepath.Join(dir, scriptFileName))
+		}
+	}
+
+	if err != nil {
+		diags.AddAttributeError(
+			c.attributePath("script"),
+			"Script Write Failed",
+			fmt.Sprintf("The %s received an unexpected error while attempting to write the script to a temporary file.", c.Kind)+
+				"\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return newResult(), diags
+	}
+
+	command := *c
+	command.Script = ""
+	command.Interpreter = nil
+	command.script = true
+	command.Name = c.interpreter()[0]
+	command.Arguments = c.scriptArguments(filepath.Join(dir, scriptFileName))
+
+	// The script is readable in the sandbox, wherever the temporary
+	// directory is.
+	command.Sandbox = c.Sandbox.withReadOnlyPath(filepath.Join(dir, scriptFileName))
+
+	return command.Run(ctx)
+}
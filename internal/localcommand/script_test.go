@@ -0,0 +1,159 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestCommandRunScript(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	testCases := map[string]struct {
+		command        Command
+		expectedStdout string
+		expectedError  string
+	}{
+		"default-interpreter": {
+			command: Command{
+				Kind:      KindDataSource,
+				Script:    "printf '%s,' \"$@\"\nprintf '%s' \"$0\"\n",
+				Arguments: []string{"a", "b c"},
+			},
+			expectedStdout: "a,b c,",
+		},
+		"default-interpreter-shebang": {
+			// The script file is run by the shell rather than executed, so
+			// that it does not depend on the temporary directory allowing
+			// execution, and its interpreter directive is only a comment.
+			command: Command{
+				Kind:      KindDataSource,
+				Script:    "#!/bin/cat\necho \"$1\"\n",
+				Arguments: []string{"shell"},
+			},
+			expectedStdout: "shell\n",
+		},
+		"shell-interpreter": {
+			command: Command{
+				Kind:        KindAction,
+				Script:      "echo \"$1\"",
+				Interpreter: []string{"bash", "--noprofile", "-c"},
+				Arguments:   []string{"hello"},
+			},
+			expectedStdout: "hello\n",
+		},
+		"interpreter": {
+			command: Command{
+				Kind:        KindAction,
+				Script:      "echo $1",
+				Interpreter: []string{"bash", "--noprofile"},
+				Arguments:   []string{"hello"},
+			},
+			expectedStdout: "hello\n",
+		},
+		"permissions": {
+			command: Command{
+				Kind:   KindEphemeralResource,
+				Script: "ls -l \"$0\" | cut -c1-10",
+			},
+			expectedStdout: "-rwx------\n",
+		},
+		"non-zero-exit-code": {
+			command: Command{
+				Kind:   KindAction,
+				Script: "exit 3",
+			},
+			expectedError: "received a non-zero exit code",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := testCase.command.Run(context.Background())
+
+			if testCase.expectedError == "" && diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if testCase.expectedError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Detail(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, diags)
+				}
+
+				return
+			}
+
+			stdout := string(got.Stdout)
+			if name == "default-interpreter" {
+				// The script prints its own path last, which must have been
+				// removed once it exited.
+				scriptPath := stdout[strings.LastIndex(stdout, ",")+1:]
+				if _, err := os.Stat(scriptPath); !os.IsNotExist(err) {
+					t.Errorf("expected script %q to be removed, got: %v", scriptPath, err)
+				}
+
+				stdout = strings.TrimSuffix(stdout, scriptPath)
+			}
+
+			if diff := cmp.Diff(testCase.expectedStdout, stdout); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCommandExecutable(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		command      Command
+		expectedName string
+		expectedPath path.Path
+	}{
+		"command": {
+			command:      Command{Name: "echo"},
+			expectedName: "echo",
+			expectedPath: path.Root("command"),
+		},
+		"script": {
+			command:      Command{Script: "echo"},
+			expectedName: "/bin/sh",
+			expectedPath: path.Root("interpreter"),
+		},
+		"script-interpreter": {
+			command:      Command{Path: path.Root("create"), Script: "print(1)", Interpreter: []string{"python3", "-u"}},
+			expectedName: "python3",
+			expectedPath: path.Root("create").AtName("interpreter"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotName, gotPath := testCase.command.Executable()
+
+			if gotName != testCase.expectedName {
+				t.Errorf("expected name %q, got %q", testCase.expectedName, gotName)
+			}
+
+			if !gotPath.Equal(testCase.expectedPath) {
+				t.Errorf("expected path %s, got %s", testCase.expectedPath, gotPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localcommand
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Arguments converts a list of strings from configuration into command
+// arguments. Mirroring the underlying os/exec support for arguments, null
+// elements are removed while empty strings are kept.
+func Arguments(list types.List) []string {
+	arguments := make([]string, 0, len(list.Elements()))
+	for _, element := range list.Elements() {
+		strElement, ok := element.(types.String)
+		if element.IsNull() || !ok {
+			continue
+		}
+
+		arguments = append(arguments, strElement.ValueString())
+	}
+
+	return arguments
+}
+
+// Environment converts a map of strings from configuration into environment
+// variables. A null map returns nil, which leaves the inherited environment
+// unchanged, and null elements are removed.
+func Environment(m types.Map) map[string]string {
+	if m.IsNull() {
+		return nil
+	}
+
+	environment := make(map[string]string, len(m.Elements()))
+	for key, value := range m.Elements() {
+		strValue, ok := value.(types.String)
+		if value.IsNull() || !ok {
+			continue
+		}
+
+		environment[key] = strValue.ValueString()
+	}
+
+	return environment
+}
+
+// Strings converts a list of strings from configuration into a slice, with
+// null elements removed. A null list returns nil.
+func Strings(list types.List) []string {
+	if list.IsNull() {
+		return nil
+	}
+
+	return Arguments(list)
+}
+
+// ValidateInheritEnvironment checks that inherited_environment_variables is
+// configured if, and only if, inherit_environment is set to "allowlist", for
+// the attributes within the block at blockPath. Unknown values are not
+// validated.
+func ValidateInheritEnvironment(blockPath path.Path, mode types.String, variables types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if mode.IsUnknown() || variables.IsUnknown() {
+		return diags
+	}
+
+	allowlist := mode.ValueString() == string(InheritEnvironmentAllowlist)
+
+	if allowlist && variables.IsNull() {
+		diags.AddAttributeError(
+			AttributePath(blockPath, "inherited_environment_variables"),
+			"Missing Attribute Configuration",
+			fmt.Sprintf("The \"inherited_environment_variables\" attribute must be configured when \"inherit_environment\" is set to %q.", InheritEnvironmentAllowlist),
+		)
+	}
+
+	if !allowlist && !variables.IsNull() {
+		diags.AddAttributeError(
+			AttributePath(blockPath, "inherited_environment_variables"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("The \"inherited_environment_variables\" attribute can only be configured when \"inherit_environment\" is set to %q.", InheritEnvironmentAllowlist),
+		)
+	}
+
+	return diags
+}
+
+// Stdin converts either a UTF-8 string or a base64 encoded string from
+// configuration into standard input data. If both are null, nil is returned,
+// which leaves the standard input of the command empty.
+func Stdin(stdin types.String, stdinBase64 types.String) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !stdinBase64.IsNull() {
+		data, err := base64.StdEncoding.DecodeString(stdinBase64.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("stdin_base64"),
+				"Invalid Base64 Value",
+				"The \"stdin_base64\" attribute must be a base64 encoded string.\n\n"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return nil, diags
+		}
+
+		return data, diags
+	}
+
+	if stdin.IsNull() {
+		return nil, diags
+	}
+
+	return []byte(stdin.ValueString()), diags
+}
+
+// ExitCodes converts a set of numbers from configuration into exit codes, with
+// null elements removed. A null set returns nil.
+func ExitCodes(set types.Set) []int {
+	if set.IsNull() {
+		return nil
+	}
+
+	codes := make([]int, 0, len(set.Elements()))
+	for _, element := range set.Elements() {
+		intElement, ok := element.(types.Int64)
+		if element.IsNull() || !ok {
+			continue
+		}
+
+		codes = append(codes, int(intElement.ValueInt64()))
+	}
+
+	return codes
+}
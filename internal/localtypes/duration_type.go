@@ -0,0 +1,140 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localtypes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable     = DurationType{}
+	_ basetypes.StringValuable    = DurationValue{}
+	_ xattr.ValidateableAttribute = DurationValue{}
+)
+
+type DurationType struct {
+	basetypes.StringType
+}
+
+func (t DurationType) Equal(o attr.Type) bool {
+	other, ok := o.(DurationType)
+
+	if !ok {
+		return false
+	}
+
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t DurationType) String() string {
+	return "DurationType"
+}
+
+func (t DurationType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	value := DurationValue{
+		StringValue: in,
+	}
+
+	return value, nil
+}
+
+func (t DurationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+
+	return stringValuable, nil
+}
+
+func (t DurationType) ValueType(ctx context.Context) attr.Value {
+	return DurationValue{}
+}
+
+func NewDurationType() DurationType {
+	return DurationType{StringType: types.StringType}
+}
+
+type DurationValue struct {
+	basetypes.StringValue
+}
+
+func (v DurationValue) Equal(o attr.Value) bool {
+	other, ok := o.(DurationValue)
+
+	if !ok {
+		return false
+	}
+
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v DurationValue) Type(ctx context.Context) attr.Type {
+	return DurationType{}
+}
+
+// ValueDuration returns the parsed duration, or zero if the value is null,
+// unknown or not a valid duration.
+func (v DurationValue) ValueDuration() time.Duration {
+	if v.IsNull() || v.IsUnknown() {
+		return 0
+	}
+
+	d, err := time.ParseDuration(v.ValueString())
+	if err != nil {
+		return 0
+	}
+
+	return d
+}
+
+// ValidateAttribute checks that the given input string is a positive duration,
+// expressed as a sequence of decimal numbers with a unit suffix, such as "30s" or "1m30s".
+// See: https://pkg.go.dev/time#ParseDuration
+func (v DurationValue) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() {
+		return
+	}
+
+	if v.IsUnknown() {
+		return
+	}
+
+	d, err := time.ParseDuration(v.ValueString())
+	if err != nil {
+		resp.Diagnostics.Append(diag.NewAttributeErrorDiagnostic(req.Path,
+			"Invalid Duration String Value",
+			"bad duration: string must be a sequence of decimal numbers with a unit suffix, such as \"30s\" or \"1m30s\": "+v.ValueString()))
+		return
+	}
+
+	if d <= 0 {
+		resp.Diagnostics.Append(diag.NewAttributeErrorDiagnostic(req.Path,
+			"Invalid Duration String Value",
+			"bad duration: duration must be greater than zero: "+v.ValueString()))
+		return
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localtypes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestDurationValueValidateAttribute(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		value    DurationValue
+		request  xattr.ValidateAttributeRequest
+		expected xattr.ValidateAttributeResponse
+	}{
+		"30s": {
+			value: DurationValue{basetypes.NewStringValue("30s")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{},
+		},
+		"1m30s": {
+			value: DurationValue{basetypes.NewStringValue("1m30s")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{},
+		},
+		"null": {
+			value: DurationValue{basetypes.NewStringNull()},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{},
+		},
+		"30": {
+			value: DurationValue{basetypes.NewStringValue("30")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{
+				Diagnostics: diag.Diagnostics{
+					diag.NewAttributeErrorDiagnostic(
+						path.Root("test"),
+						"Invalid Duration String Value",
+						"bad duration: string must be a sequence of decimal numbers with a unit suffix, such as \"30s\" or \"1m30s\": 30",
+					),
+				},
+			},
+		},
+		"0s": {
+			value: DurationValue{basetypes.NewStringValue("0s")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{
+				Diagnostics: diag.Diagnostics{
+					diag.NewAttributeErrorDiagnostic(
+						path.Root("test"),
+						"Invalid Duration String Value",
+						"bad duration: duration must be greater than zero: 0s",
+					),
+				},
+			},
+		},
+		"-5s": {
+			value: DurationValue{basetypes.NewStringValue("-5s")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{
+				Diagnostics: diag.Diagnostics{
+					diag.NewAttributeErrorDiagnostic(
+						path.Root("test"),
+						"Invalid Duration String Value",
+						"bad duration: duration must be greater than zero: -5s",
+					),
+				},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := xattr.ValidateAttributeResponse{}
+
+			testCase.value.ValidateAttribute(context.Background(), testCase.request, &got)
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Errorf("unexpected response: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDurationValueValueDuration(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		value    DurationValue
+		expected time.Duration
+	}{
+		"1m30s": {
+			value:    DurationValue{basetypes.NewStringValue("1m30s")},
+			expected: 90 * time.Second,
+		},
+		"null": {
+			value:    DurationValue{basetypes.NewStringNull()},
+			expected: 0,
+		},
+		"unknown": {
+			value:    DurationValue{basetypes.NewStringUnknown()},
+			expected: 0,
+		},
+		"invalid": {
+			value:    DurationValue{basetypes.NewStringValue("invalid")},
+			expected: 0,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := testCase.value.ValueDuration(); got != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, got)
+			}
+		})
+	}
+}
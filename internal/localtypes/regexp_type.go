@@ -0,0 +1,132 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localtypes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable     = RegexpType{}
+	_ basetypes.StringValuable    = RegexpValue{}
+	_ xattr.ValidateableAttribute = RegexpValue{}
+)
+
+type RegexpType struct {
+	basetypes.StringType
+}
+
+func (t RegexpType) Equal(o attr.Type) bool {
+	other, ok := o.(RegexpType)
+
+	if !ok {
+		return false
+	}
+
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t RegexpType) String() string {
+	return "RegexpType"
+}
+
+func (t RegexpType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	value := RegexpValue{
+		StringValue: in,
+	}
+
+	return value, nil
+}
+
+func (t RegexpType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+
+	return stringValuable, nil
+}
+
+func (t RegexpType) ValueType(ctx context.Context) attr.Value {
+	return RegexpValue{}
+}
+
+func NewRegexpType() RegexpType {
+	return RegexpType{StringType: types.StringType}
+}
+
+type RegexpValue struct {
+	basetypes.StringValue
+}
+
+func (v RegexpValue) Equal(o attr.Value) bool {
+	other, ok := o.(RegexpValue)
+
+	if !ok {
+		return false
+	}
+
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v RegexpValue) Type(ctx context.Context) attr.Type {
+	return RegexpType{}
+}
+
+// ValueRegexp returns the compiled regular expression, or nil if the value is
+// null, unknown or not a valid regular expression.
+func (v RegexpValue) ValueRegexp() *regexp.Regexp {
+	if v.IsNull() || v.IsUnknown() {
+		return nil
+	}
+
+	re, err := regexp.Compile(v.ValueString())
+	if err != nil {
+		return nil
+	}
+
+	return re
+}
+
+// ValidateAttribute checks that the given input string is a valid regular
+// expression, using the same RE2 syntax as the built-in Terraform regex function.
+// See: https://pkg.go.dev/regexp/syntax
+func (v RegexpValue) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() {
+		return
+	}
+
+	if v.IsUnknown() {
+		return
+	}
+
+	if _, err := regexp.Compile(v.ValueString()); err != nil {
+		resp.Diagnostics.Append(diag.NewAttributeErrorDiagnostic(req.Path,
+			"Invalid Regular Expression String Value",
+			"bad regular expression: "+err.Error()))
+		return
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package localtypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestRegexpValueValidateAttribute(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		value    RegexpValue
+		request  xattr.ValidateAttributeRequest
+		expected xattr.ValidateAttributeResponse
+	}{
+		"valid": {
+			value: RegexpValue{basetypes.NewStringValue(`^error: \d+`)},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{},
+		},
+		"null": {
+			value: RegexpValue{basetypes.NewStringNull()},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{},
+		},
+		"unclosed": {
+			value: RegexpValue{basetypes.NewStringValue("(unclosed")},
+			request: xattr.ValidateAttributeRequest{
+				Path: path.Root("test"),
+			},
+			expected: xattr.ValidateAttributeResponse{
+				Diagnostics: diag.Diagnostics{
+					diag.NewAttributeErrorDiagnostic(
+						path.Root("test"),
+						"Invalid Regular Expression String Value",
+						"bad regular expression: error parsing regexp: missing closing ): `(unclosed`",
+					),
+				},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := xattr.ValidateAttributeResponse{}
+
+			testCase.value.ValidateAttribute(context.Background(), testCase.request, &got)
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Errorf("unexpected response: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRegexpValueValueRegexp(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		value    RegexpValue
+		expected string
+	}{
+		"valid": {
+			value:    RegexpValue{basetypes.NewStringValue(`^error: \d+`)},
+			expected: `^error: \d+`,
+		},
+		"null": {
+			value: RegexpValue{basetypes.NewStringNull()},
+		},
+		"unknown": {
+			value: RegexpValue{basetypes.NewStringUnknown()},
+		},
+		"invalid": {
+			value: RegexpValue{basetypes.NewStringValue("(unclosed")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			if re := testCase.value.ValueRegexp(); re != nil {
+				got = re.String()
+			}
+
+			if got != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package localuser resolves the names or numeric IDs of users and groups, as
+// configured for the owner of a local file or the user a local command runs
+// as.
+package localuser
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// LookupUser returns the ID and account of the named user. A numeric ID
+// without a user account is returned without one.
+func LookupUser(name string) (uint32, *user.User, error) {
+	id, idErr := ParseID(name)
+
+	var account *user.User
+	var err error
+	if idErr == nil {
+		account, err = user.LookupId(name)
+	} else {
+		account, err = user.Lookup(name)
+	}
+
+	if err == nil {
+		uid, err := ParseID(account.Uid)
+		if err != nil {
+			return 0, nil, fmt.Errorf("user %s has an invalid user ID %q", name, account.Uid)
+		}
+
+		return uid, account, nil
+	}
+
+	if _, ok := err.(user.UnknownUserIdError); ok {
+		return id, nil, nil
+	}
+
+	return 0, nil, fmt.Errorf("unable to look up user %s: %w", name, err)
+}
+
+// LookupGroup returns the ID of the named group. A numeric ID is returned as
+// is, whether or not the group exists.
+func LookupGroup(name string) (uint32, error) {
+	if id, err := ParseID(name); err == nil {
+		return id, nil
+	}
+
+	group, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unable to look up group %s: %w", name, err)
+	}
+
+	gid, err := ParseID(group.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("group %s has an invalid group ID %q", name, group.Gid)
+	}
+
+	return gid, nil
+}
+
+// LookupOwner resolves the names or numeric IDs of a user and group to the
+// IDs of the owner and group of a file. An empty name resolves to -1, which
+// os.Chown leaves unchanged.
+func LookupOwner(userName, groupName string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if userName != "" {
+		id, _, err := LookupUser(userName)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		uid = int(id)
+	}
+
+	if groupName != "" {
+		id, err := LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		gid = int(id)
+	}
+
+	return uid, gid, nil
+}
+
+// ParseID parses a numeric user or group ID.
+func ParseID(id string) (uint32, error) {
+	value, err := strconv.ParseUint(id, 10, 32)
+
+	return uint32(value), err
+}
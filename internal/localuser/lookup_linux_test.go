@@ -0,0 +1,77 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package localuser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupOwner(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		user          string
+		group         string
+		expectedUID   int
+		expectedGID   int
+		expectedError string
+	}{
+		"none": {
+			expectedUID: -1,
+			expectedGID: -1,
+		},
+		"names": {
+			user:        "root",
+			group:       "root",
+			expectedUID: 0,
+			expectedGID: 0,
+		},
+		"unknown-ids": {
+			user:        "4321",
+			group:       "4321",
+			expectedUID: 4321,
+			expectedGID: 4321,
+		},
+		"group-only": {
+			group:       "0",
+			expectedUID: -1,
+			expectedGID: 0,
+		},
+		"unknown-user": {
+			user:          "terraform-provider-local-unknown",
+			expectedError: "unable to look up user terraform-provider-local-unknown",
+		},
+		"unknown-group": {
+			group:         "terraform-provider-local-unknown",
+			expectedError: "unable to look up group terraform-provider-local-unknown",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			uid, gid, err := LookupOwner(testCase.user, testCase.group)
+
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if uid != testCase.expectedUID || gid != testCase.expectedGID {
+				t.Errorf("expected %d:%d, got: %d:%d", testCase.expectedUID, testCase.expectedGID, uid, gid)
+			}
+		})
+	}
+}
@@ -4,31 +4,53 @@
 package provider
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/action/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
 )
 
 var (
-	_ action.Action = (*localCommandAction)(nil)
+	_ action.Action                   = (*localCommandAction)(nil)
+	_ action.ActionWithConfigure      = (*localCommandAction)(nil)
+	_ action.ActionWithModifyPlan     = (*localCommandAction)(nil)
+	_ action.ActionWithValidateConfig = (*localCommandAction)(nil)
 )
 
 func NewLocalCommandAction() action.Action {
 	return &localCommandAction{}
 }
 
-type localCommandAction struct{}
+type localCommandAction struct {
+	providerData *localProviderData
+}
+
+func (a *localCommandAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	a.providerData = providerData
+}
 
 func (a *localCommandAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_command"
@@ -37,53 +59,186 @@ func (a *localCommandAction) Metadata(ctx context.Context, req action.MetadataRe
 func (a *localCommandAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Invokes an executable on the local machine. All environment variables visible to the Terraform process are passed through " +
-			"to the child process. Additional environment variables can be explicitly set via the `environment` attribute; these are merged on top of " +
-			"the inherited environment, with the provided values taking precedence. After the child process successfully executes, the `stdout` will be " +
-			"returned for Terraform to display to the user.\n\n" +
-			"Any non-zero exit code will be treated as an error and will return a diagnostic to Terraform containing the `stderr` message if available.",
-		Attributes: map[string]schema.Attribute{
+			"to the child process, unless limited with `inherit_environment`. Additional environment variables can be explicitly set via the `environment` attribute; these are merged on top of " +
+			"the inherited environment, with the provided values taking precedence. While the child process executes, the lines it writes to `stdout` and `stderr` are " +
+			"streamed to Terraform to display to the user, batched every `progress_flush_interval`.\n\n" +
+			"Any non-zero exit code will be treated as an error and will return a diagnostic to Terraform containing the `stderr` message if available. " +
+			"The exit codes that indicate success can be changed with `success_exit_codes`, and regular expressions matching the command's output can also indicate " +
+			"success or failure regardless of the exit code, with `success_stdout_regex`, `success_stderr_regex`, `failure_stdout_regex` and `failure_stderr_regex`.",
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
 			"command": schema.StringAttribute{
-				Description: "Executable name to be discovered on the PATH or absolute path to executable.",
-				Required:    true,
-			},
-			"arguments": schema.ListAttribute{
-				MarkdownDescription: "Arguments to be passed to the given command. Any `null` arguments will be removed from the list.",
-				ElementType:         types.StringType,
+				MarkdownDescription: "Executable name to be discovered on the PATH or absolute path to executable. Exactly one of `command` or `script` must be set.",
 				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("command"), path.MatchRoot("script")),
+				},
 			},
 			"stdin": schema.StringAttribute{
 				Description: "Data to be passed to the given command's standard input.",
 				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("stdin_base64")),
+				},
 			},
-			"working_directory": schema.StringAttribute{
-				Description: "The directory path where the command should be executed, either an absolute path or relative to the Terraform working directory. If not provided, defaults to the Terraform working directory.",
-				Optional:    true,
+			"progress_flush_interval": schema.StringAttribute{
+				MarkdownDescription: "How often the lines written by the command are sent to Terraform to display, as a duration string such as `500ms` or `5s`. " +
+					"Lines written within the same interval are displayed together. Defaults to `1s`.",
+				CustomType: localtypes.NewDurationType(),
+				Optional:   true,
 			},
-			"environment": schema.MapAttribute{
-				Description: "Environment variables to set for the command. These are merged with the environment variables inherited from the Terraform process, with these values taking precedence.",
-				ElementType: types.StringType,
+			"progress_prefix": schema.StringAttribute{
+				Description: "A prefix prepended to every line written by the command when it is displayed, for example, to distinguish the output of multiple actions.",
 				Optional:    true,
 			},
+		}, actionCommandAttributes(), actionScriptAttributes(), actionResultAttributes()),
+		Blocks: map[string]schema.Block{
+			"output_file": schema.SingleNestedBlock{
+				MarkdownDescription: "Writes the standard output of the command directly to a file, rather than displaying it to the user, such as when the command generates a large " +
+					"or binary artifact. The file is created with the same permission handling as the `local_file` resource, and is removed if the command fails. " +
+					"The standard output is not limited by `max_output_bytes`, and cannot be matched by `success_stdout_regex` or `failure_stdout_regex`.",
+				Validators: []validator.Object{
+					objectvalidator.AlsoRequires(path.MatchRelative().AtName("filename")),
+					objectvalidator.ConflictsWith(
+						path.MatchRoot("success_stdout_regex"),
+						path.MatchRoot("failure_stdout_regex"),
+					),
+				},
+				Attributes: map[string]schema.Attribute{
+					"filename": schema.StringAttribute{
+						Description: "The path to the file that will be created. Missing parent directories will be created. " +
+							"If the file already exists, it will be overridden with the output of the command. Must be set when the block is configured.",
+						Optional: true,
+					},
+					"file_permission": schema.StringAttribute{
+						CustomType: localtypes.NewFilePermissionType(),
+						MarkdownDescription: "Permissions to set for the output file (before umask), expressed as string in " +
+							"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation). Defaults to `\"0777\"`.",
+						Optional: true,
+					},
+					"directory_permission": schema.StringAttribute{
+						CustomType: localtypes.NewFilePermissionType(),
+						MarkdownDescription: "Permissions to set for directories created (before umask), expressed as string in " +
+							"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation). Defaults to `\"0777\"`.",
+						Optional: true,
+					},
+				},
+			},
+			"retry":   actionRetryBlock(),
+			"limits":  actionLimitsBlock(),
+			"sandbox": actionSandboxBlock(),
 		},
 	}
 }
 
+func (a *localCommandAction) ValidateConfig(ctx context.Context, req action.ValidateConfigRequest, resp *action.ValidateConfigResponse) {
+	var inheritEnvironment types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherit_environment"), &inheritEnvironment)...)
+
+	var inheritedEnvironmentVariables types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherited_environment_variables"), &inheritedEnvironmentVariables)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Empty(), inheritEnvironment, inheritedEnvironmentVariables)...)
+
+	var runAsUser, runAsGroup types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_user"), &runAsUser)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_group"), &runAsGroup)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Empty(), runAsUser, runAsGroup)...)
+
+	limits, diags := getConfigBlock[localCommandLimitsModel](ctx, req.Config, path.Root("limits"))
+	resp.Diagnostics.Append(diags...)
+
+	sandbox, diags := getConfigBlock[localCommandSandboxModel](ctx, req.Config, path.Root("sandbox"))
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateLimits(path.Empty(), limits.limits())...)
+	resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Empty(), sandbox.sandbox())...)
+}
+
 type localCommandActionModel struct {
-	Command          types.String `tfsdk:"command"`
-	Arguments        types.List   `tfsdk:"arguments"`
-	Stdin            types.String `tfsdk:"stdin"`
-	WorkingDirectory types.String `tfsdk:"working_directory"`
-	Environment      types.Map    `tfsdk:"environment"`
+	Command                       types.String                 `tfsdk:"command"`
+	Arguments                     types.List                   `tfsdk:"arguments"`
+	Script                        types.String                 `tfsdk:"script"`
+	Interpreter                   types.List                   `tfsdk:"interpreter"`
+	Stdin                         types.String                 `tfsdk:"stdin"`
+	StdinBase64                   types.String                 `tfsdk:"stdin_base64"`
+	WorkingDirectory              types.String                 `tfsdk:"working_directory"`
+	MaxOutputBytes                types.Int64                  `tfsdk:"max_output_bytes"`
+	OutputTruncation              types.String                 `tfsdk:"output_truncation"`
+	ProgressFlushInterval         localtypes.DurationValue     `tfsdk:"progress_flush_interval"`
+	ProgressPrefix                types.String                 `tfsdk:"progress_prefix"`
+	Timeout                       localtypes.DurationValue     `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue     `tfsdk:"termination_grace_period"`
+	Environment                   types.Map                    `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                    `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String                 `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                   `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String                 `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String                 `tfsdk:"run_as_group"`
+	SuccessExitCodes              types.Set                    `tfsdk:"success_exit_codes"`
+	SuccessStdoutRegex            localtypes.RegexpValue       `tfsdk:"success_stdout_regex"`
+	SuccessStderrRegex            localtypes.RegexpValue       `tfsdk:"success_stderr_regex"`
+	FailureStdoutRegex            localtypes.RegexpValue       `tfsdk:"failure_stdout_regex"`
+	FailureStderrRegex            localtypes.RegexpValue       `tfsdk:"failure_stderr_regex"`
+	Retry                         *localCommandRetryModel      `tfsdk:"retry"`
+	Limits                        *localCommandLimitsModel     `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel    `tfsdk:"sandbox"`
+	OutputFile                    *localCommandOutputFileModel `tfsdk:"output_file"`
+}
+
+type localCommandOutputFileModel struct {
+	Filename            types.String                   `tfsdk:"filename"`
+	FilePermission      localtypes.FilePermissionValue `tfsdk:"file_permission"`
+	DirectoryPermission localtypes.FilePermissionValue `tfsdk:"directory_permission"`
 }
 
 func (a *localCommandAction) ModifyPlan(ctx context.Context, req action.ModifyPlanRequest, resp *action.ModifyPlanResponse) {
-	var command types.String
+	var command, script types.String
 	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("command"), &command)...)
-	if resp.Diagnostics.HasError() || command.IsUnknown() {
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("script"), &script)...)
+
+	var interpreter types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("interpreter"), &interpreter)...)
+
+	var runAsUser, runAsGroup types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_user"), &runAsUser)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_group"), &runAsGroup)...)
+
+	if resp.Diagnostics.HasError() || command.IsUnknown() || script.IsUnknown() || interpreter.IsUnknown() || runAsUser.IsUnknown() || runAsGroup.IsUnknown() {
 		return
 	}
 
-	resp.Diagnostics.Append(findCommand(command.ValueString()))
+	executable := localcommand.Command{
+		Name:        command.ValueString(),
+		Script:      script.ValueString(),
+		Interpreter: localcommand.Strings(interpreter),
+		RunAs: localcommand.RunAs{
+			User:  runAsUser.ValueString(),
+			Group: runAsGroup.ValueString(),
+		},
+	}
+
+	name, attributePath := executable.Executable()
+	resp.Diagnostics.Append(findCommand(a.providerData, attributePath, localcommand.KindAction, name, executable.RunAs)...)
+
+	var filename types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("output_file").AtName("filename"), &filename)...)
+	if resp.Diagnostics.HasError() || filename.IsNull() || filename.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(a.providerData.checkPath(path.Root("output_file").AtName("filename"), filename.ValueString())...)
 }
 
 func (a *localCommandAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
@@ -93,113 +248,100 @@ func (a *localCommandAction) Invoke(ctx context.Context, req action.InvokeReques
 		return
 	}
 
-	// Prep the command
-	command := config.Command.ValueString()
-	resp.Diagnostics.Append(findCommand(command))
+	stdin, diags := localcommand.Stdin(config.Stdin, config.StdinBase64)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	arguments := make([]string, 0)
-	for _, element := range config.Arguments.Elements() {
-		strElement, ok := element.(types.String)
-		// Mirroring the underlying os/exec Command support for args (no nil arguments, but does support empty strings)
-		if element.IsNull() || !ok {
-			continue
-		}
-
-		arguments = append(arguments, strElement.ValueString())
+	// Prep the command
+	command := localcommand.Command{
+		Kind:                          localcommand.KindAction,
+		Name:                          config.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(config.Arguments),
+		Script:                        config.Script.ValueString(),
+		Interpreter:                   localcommand.Strings(config.Interpreter),
+		WorkingDirectory:              config.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(config.Environment),
+		SensitiveEnvironment:          localcommand.Environment(config.SensitiveEnvironment),
+		InheritEnvironment:            localcommand.InheritEnvironment(config.InheritEnvironment.ValueString()),
+		InheritedEnvironmentVariables: localcommand.Strings(config.InheritedEnvironmentVariables),
+		RunAs: localcommand.RunAs{
+			User:  config.RunAsUser.ValueString(),
+			Group: config.RunAsGroup.ValueString(),
+		},
+		SuccessExitCodes:   localcommand.ExitCodes(config.SuccessExitCodes),
+		SuccessStdoutRegex: config.SuccessStdoutRegex.ValueRegexp(),
+		SuccessStderrRegex: config.SuccessStderrRegex.ValueRegexp(),
+		FailureStdoutRegex: config.FailureStdoutRegex.ValueRegexp(),
+		FailureStderrRegex: config.FailureStderrRegex.ValueRegexp(),
+		Retry:              config.Retry.retry(),
+		Limits:             config.Limits.limits(),
+		Sandbox:            config.Sandbox.sandbox(),
+		Stdin:              stdin,
+		MaxOutputBytes:     config.MaxOutputBytes.ValueInt64(),
+		OutputTruncation:   localcommand.OutputTruncation(config.OutputTruncation.ValueString()),
+		Progress: func(message string) {
+			resp.SendProgress(action.InvokeProgressEvent{
+				Message: message,
+			})
+		},
+		ProgressFlushInterval:  config.ProgressFlushInterval.ValueDuration(),
+		ProgressPrefix:         config.ProgressPrefix.ValueString(),
+		Timeout:                config.Timeout.ValueDuration(),
+		TerminationGracePeriod: config.TerminationGracePeriod.ValueDuration(),
 	}
 
-	cmd := exec.CommandContext(ctx, command, arguments...)
-
-	cmd.Dir = config.WorkingDirectory.ValueString()
+	name, attributePath := command.Executable()
+	resp.Diagnostics.Append(findCommand(a.providerData, attributePath, command.Kind, name, command.RunAs)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if !config.Environment.IsNull() {
-		cmd.Env = os.Environ()
-		for key, value := range config.Environment.Elements() {
-			strValue, ok := value.(types.String)
-			if !ok {
-				continue
-			}
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, strValue.ValueString()))
+	var output *localFileWriter
+	if config.OutputFile != nil {
+		output, diags = config.OutputFile.create(a.providerData)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-	}
 
-	if !config.Stdin.IsNull() {
-		cmd.Stdin = bytes.NewReader([]byte(config.Stdin.ValueString()))
+		command.Stdout = output
 	}
 
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
+	// Run the command, streaming its output to Terraform to display to the practitioner as progress. Each progress
+	// message gets a prefix per line, so lines are batched together every flush interval to keep the output readable.
+	_, diags = command.Run(ctx)
+	resp.Diagnostics.Append(diags...)
 
-	tflog.Trace(ctx, "Executing local command", map[string]interface{}{"command": cmd.String()})
+	if output != nil {
+		resp.Diagnostics.Append(output.close(diags.HasError())...)
+	}
+}
 
-	// Run the command
-	stdout, err := cmd.Output()
-	stdoutStr := string(stdout)
-	stderrStr := stderr.String()
+// create creates the file the standard output of the command is written to.
+func (m *localCommandOutputFileModel) create(providerData *localProviderData) (*localFileWriter, diag.Diagnostics) {
+	attributePath := path.Root("output_file").AtName("filename")
+	destination := m.Filename.ValueString()
 
-	if err != nil {
-		if len(stderrStr) > 0 {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("command"),
-				"Command Execution Failed",
-				"The action received an unexpected error while attempting to execute the command."+
-					"\n\n"+
-					fmt.Sprintf("Command: %s\n", cmd.String())+
-					fmt.Sprintf("Command Error: %s\n", stderrStr)+
-					fmt.Sprintf("State: %s", err),
-			)
-			return
-		}
-
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Command Execution Failed",
-			"The action received an unexpected error while attempting to execute the command."+
-				"\n\n"+
-				fmt.Sprintf("Command: %s\n", cmd.Path)+
-				fmt.Sprintf("Error: %s", err),
-		)
-		return
+	diags := providerData.checkPath(attributePath, destination)
+	if diags.HasError() {
+		return nil, diags
 	}
 
-	tflog.Trace(ctx, "Executed local command", map[string]interface{}{"command": cmd.String(), "stdout": stdoutStr, "stderr": stderrStr})
-
-	// Send the STDOUT to Terraform to display to the practitioner. The underlying action protocol supports streaming the
-	// STDOUT line-by-line in real-time, although each progress message gets a prefix per line, so it'd be difficult
-	// to read without batching lines together with an arbitrary time interval (this can be improved later if needed).
-	resp.SendProgress(action.InvokeProgressEvent{
-		Message: fmt.Sprintf("\n\n%s\n", stdoutStr),
-	})
-}
+	filePerm := parseFilePermission(m.FilePermission, localFileDefaultPermission)
+	dirPerm := parseFilePermission(m.DirectoryPermission, localFileDefaultPermission)
 
-func findCommand(command string) diag.Diagnostic {
-	if _, err := exec.LookPath(command); err != nil {
-		return diag.NewAttributeErrorDiagnostic(
-			path.Root("command"),
-			"Command Lookup Failed",
-			"The action received an unexpected error while attempting to find the command."+
-				"\n\n"+
-				"The command must be accessible according to the platform where Terraform is running."+
-				"\n\n"+
-				"If the expected command should be automatically found on the platform where Terraform is running, "+
-				"ensure that the command is in an expected directory. On Unix-based platforms, these directories are "+
-				"typically searched based on the '$PATH' environment variable. On Windows-based platforms, these directories "+
-				"are typically searched based on the '%PATH%' environment variable."+
-				"\n\n"+
-				"If the expected command is relative to the Terraform configuration, it is recommended that the command name includes "+
-				"the interpolated value of 'path.module' before the command name to ensure that it is compatible with varying module usage. For example: \"${path.module}/my-command\""+
-				"\n\n"+
-				"The command must also be executable according to the platform where Terraform is running. On Unix-based platforms, the file on the filesystem must have the executable bit set. "+
-				"On Windows-based platforms, no action is typically necessary."+
-				"\n\n"+
-				fmt.Sprintf("Platform: %s\n", runtime.GOOS)+
-				fmt.Sprintf("Command: %s\n", command)+
-				fmt.Sprintf("Error: %s", err),
+	output, err := createLocalFile(destination, filePerm, dirPerm)
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Create local command output file error",
+			"An unexpected error occurred while creating the file\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
 		)
+		return nil, diags
 	}
 
-	return nil
+	return output, diags
 }
@@ -246,6 +246,41 @@ action "local_command" "test" {
 	})
 }
 
+func TestLocalCommandAction_allowed_commands(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "local" {
+  allowed_commands = ["/opt/allowed/*"]
+}
+
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command   = "bash"
+    arguments = ["-c", "echo hello"]
+  }
+}`,
+				ExpectError: regexp.MustCompile(`The command is not allowed by the allowed_commands provider configuration`),
+			},
+		},
+	})
+}
+
 func TestLocalCommandAction_bash_environment(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -338,3 +373,327 @@ func assertTestFile(t *testing.T, filePath, expectedContent string) error {
 
 	return nil
 }
+
+func TestLocalCommandAction_timeout(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command   = "sleep"
+    arguments = ["30"]
+    timeout   = "1s"
+  }
+}`,
+				ExpectError: regexp.MustCompile(`Command Timed Out`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_success_exit_codes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command            = "sh"
+    arguments          = ["-c", "exit 2"]
+    success_exit_codes = [0, 2]
+  }
+}`,
+			},
+			{
+				Config: `
+resource "terraform_data" "test" {
+  input = "replace"
+
+  lifecycle {
+    action_trigger {
+      events  = [after_create, after_update]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command              = "sh"
+    arguments            = ["-c", "echo 'ERROR: failed' >&2"]
+    failure_stderr_regex = "ERROR"
+  }
+}`,
+				ExpectError: regexp.MustCompile(`its standard error matched "failure_stderr_regex"`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_output_file(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "new", "test_file.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command   = "printf"
+    arguments = ["%%s", "hello"]
+
+    output_file {
+      filename        = %q
+      file_permission = "0600"
+    }
+  }
+}`, testFile),
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "hello")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_output_file_stdout_regex(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command              = "echo"
+    success_stdout_regex = "ok"
+
+    output_file {
+      filename = "output.txt"
+    }
+  }
+}`,
+				ExpectError: regexp.MustCompile(`Attribute "success_stdout_regex" cannot be specified when "output_file" is\s+specified`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_inherit_environment(t *testing.T) {
+	t.Setenv("LOCAL_COMMAND_INHERITED", "inherited")
+	t.Setenv("LOCAL_COMMAND_NOT_INHERITED", "not inherited")
+
+	tempDir := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command                         = "sh"
+    arguments                       = ["-c", "echo \"$LOCAL_COMMAND_INHERITED|$LOCAL_COMMAND_NOT_INHERITED|$LOCAL_COMMAND_SET\" > test_file.txt"]
+    inherit_environment             = "allowlist"
+    inherited_environment_variables = ["LOCAL_COMMAND_INHERITED"]
+    environment = {
+      LOCAL_COMMAND_SET = "set"
+    }
+    working_directory = %q
+  }
+}`, tempDir),
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, filepath.Join(tempDir, "test_file.txt"), "inherited||set\n")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_sensitive_environment(t *testing.T) {
+	tempDir := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+variable "token" {
+  type      = string
+  default   = "s3cr3t"
+  sensitive = true
+}
+
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command   = "sh"
+    arguments = ["-c", "echo \"$LOCAL_COMMAND_TOKEN\" > test_file.txt"]
+    sensitive_environment = {
+      LOCAL_COMMAND_TOKEN = var.token
+    }
+    working_directory = %q
+  }
+}`, tempDir),
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, filepath.Join(tempDir, "test_file.txt"), "s3cr3t\n")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_stdin_base64(t *testing.T) {
+	tempDir := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command           = "sh"
+    arguments         = ["-c", "cat > test_file.txt"]
+    stdin_base64      = base64encode("hello\n")
+    working_directory = %q
+  }
+}`, tempDir),
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, filepath.Join(tempDir, "test_file.txt"), "hello\n")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandAction_progress(t *testing.T) {
+	tempDir := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		// Actions are only available in 1.14 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.local_command.test]
+    }
+  }
+}
+
+action "local_command" "test" {
+  config {
+    command                 = "sh"
+    arguments               = ["-c", "echo first; sleep 1; echo second >&2; echo done > test_file.txt"]
+    progress_flush_interval = "100ms"
+    progress_prefix         = "[test] "
+    working_directory       = %q
+  }
+}`, tempDir),
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, filepath.Join(tempDir, "test_file.txt"), "done\n")
+				},
+			},
+		},
+	})
+}
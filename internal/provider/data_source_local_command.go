@@ -4,29 +4,51 @@
 package provider
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
-	"os/exec"
-	"runtime"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
 )
 
 var (
-	_ datasource.DataSource = (*localCommandDataSource)(nil)
+	_ datasource.DataSource                   = (*localCommandDataSource)(nil)
+	_ datasource.DataSourceWithConfigure      = (*localCommandDataSource)(nil)
+	_ datasource.DataSourceWithValidateConfig = (*localCommandDataSource)(nil)
 )
 
 func NewLocalCommandDataSource() datasource.DataSource {
 	return &localCommandDataSource{}
 }
 
-type localCommandDataSource struct{}
+type localCommandDataSource struct {
+	providerData *localProviderData
+}
+
+func (a *localCommandDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	a.providerData = providerData
+}
 
 func (a *localCommandDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_command"
@@ -35,13 +57,17 @@ func (a *localCommandDataSource) Metadata(ctx context.Context, req datasource.Me
 func (a *localCommandDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Runs an executable on the local machine and returns the exit code, standard output data (`stdout`), and standard error data (`stderr`). " +
-			"All environment variables visible to the Terraform process are passed through to the child process. Both `stdout` and `stderr` returned by this data source " +
+			"All environment variables visible to the Terraform process are passed through to the child process, unless limited with `inherit_environment`. " +
+			"Additional environment variables can be explicitly set via the `environment` attribute. Both `stdout` and `stderr` returned by this data source " +
 			"are UTF-8 strings, which can be decoded into [Terraform values](https://developer.hashicorp.com/terraform/language/expressions/types) for use elsewhere in the Terraform configuration. " +
 			"There are built-in decoding functions such as [`jsondecode`](https://developer.hashicorp.com/terraform/language/functions/jsondecode) or [`yamldecode`](https://developer.hashicorp.com/terraform/language/functions/yamldecode), " +
-			"and more specialized [decoding functions](https://developer.hashicorp.com/terraform/plugin/framework/functions/concepts) can be built with a Terraform provider." +
+			"and more specialized [decoding functions](https://developer.hashicorp.com/terraform/plugin/framework/functions/concepts) can be built with a Terraform provider. " +
+			"Alternatively, set `output_format` to decode `stdout` into `stdout_decoded`, so that decoding errors are reported on the command itself." +
 			"\n\n" +
 			"Any non-zero exit code returned by the command will be treated as an error and will return a diagnostic to Terraform containing the `stderr` message if available. " +
-			"If a non-zero exit code is expected by the command, set `allow_non_zero_exit_code` to `true`." +
+			"If a non-zero exit code is expected by the command, set `allow_non_zero_exit_code` to `true`, or list the exit codes that indicate success in `success_exit_codes`. " +
+			"Regular expressions matching the command's output can also indicate success or failure regardless of the exit code, with `success_stdout_regex`, " +
+			"`success_stderr_regex`, `failure_stdout_regex` and `failure_stderr_regex`." +
 			"\n\n" +
 			"~> **Warning** This mechanism is provided as an \"escape hatch\" for exceptional situations where a first-class Terraform provider is not more appropriate. " +
 			"Its capabilities are limited in comparison to a true data source, and implementing a data source via a local executable is likely to hurt the " +
@@ -50,29 +76,29 @@ func (a *localCommandDataSource) Schema(ctx context.Context, req datasource.Sche
 			"\n\n" +
 			"~> **Warning** HCP Terraform and Terraform Enterprise do not guarantee availability of any particular language runtimes or external programs beyond standard shell utilities, " +
 			"so it is not recommended to use this data source within configurations that are applied within either.",
-		Attributes: map[string]schema.Attribute{
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
 			"command": schema.StringAttribute{
-				Description: "Executable name to be discovered on the PATH or absolute path to executable.",
-				Required:    true,
-			},
-			"arguments": schema.ListAttribute{
-				MarkdownDescription: "Arguments to be passed to the given command. Any `null` arguments will be removed from the list.",
-				ElementType:         types.StringType,
+				MarkdownDescription: "Executable name to be discovered on the PATH or absolute path to executable. Exactly one of `command` or `script` must be set.",
 				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("command"), path.MatchRoot("script")),
+				},
 			},
 			"stdin": schema.StringAttribute{
 				MarkdownDescription: "Data to be passed to the given command's standard input as a UTF-8 string. [Terraform values](https://developer.hashicorp.com/terraform/language/expressions/types) can be encoded " +
 					"by any Terraform encode function, for example, [`jsonencode`](https://developer.hashicorp.com/terraform/language/functions/jsonencode).",
 				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("stdin_base64")),
+				},
 			},
-			"working_directory": schema.StringAttribute{
-				Description: "The directory path where the command should be executed, either an absolute path or relative to the Terraform working directory. If not provided, defaults to the Terraform working directory.",
-				Optional:    true,
-			},
-			"allow_non_zero_exit_code": schema.BoolAttribute{
-				MarkdownDescription: "Indicates that the command returning a non-zero exit code should be treated as a successful execution. " +
-					"Further assertions can be made of the `exit_code` value with the [`check` block](https://developer.hashicorp.com/terraform/language/block/check). Defaults to false.",
+			"output_format": schema.StringAttribute{
+				MarkdownDescription: "The format used to decode the command's standard output into `stdout_decoded`. Valid values are `json`, `yaml`, `toml`, `dotenv` and `raw`. " +
+					"If the output cannot be decoded, the data source returns a diagnostic to Terraform. If not provided, `stdout_decoded` is `null`.",
 				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(localcommand.OutputFormats()...),
+				},
 			},
 			"exit_code": schema.Int64Attribute{
 				MarkdownDescription: "The exit code returned by the command. By default, if the exit code is non-zero, the data source will return a diagnostic to Terraform. " +
@@ -84,144 +110,192 @@ func (a *localCommandDataSource) Schema(ctx context.Context, req datasource.Sche
 					"which can then be decoded by any Terraform decode function, for example, [`jsondecode`](https://developer.hashicorp.com/terraform/language/functions/jsondecode).",
 				Computed: true,
 			},
+			"stdout_base64": schema.StringAttribute{
+				MarkdownDescription: "Data returned from the command's standard output stream, encoded as a base64 string. Unlike `stdout`, this preserves output that is not valid UTF-8, " +
+					"such as generated keys or archives, and can be decoded with [`base64decode`](https://developer.hashicorp.com/terraform/language/functions/base64decode) or passed to `content_base64` of `local_file`.",
+				Computed: true,
+			},
+			"stderr_base64": schema.StringAttribute{
+				Description: "Data returned from the command's standard error stream, encoded as a base64 string. This preserves output that is not valid UTF-8 and will be " +
+					"populated regardless of the exit code returned.",
+				Computed: true,
+			},
+			"stdout_truncated": schema.BoolAttribute{
+				Description: "Whether output was discarded from the command's standard output stream because it exceeded `max_output_bytes`.",
+				Computed:    true,
+			},
+			"stderr_truncated": schema.BoolAttribute{
+				Description: "Whether output was discarded from the command's standard error stream because it exceeded `max_output_bytes`.",
+				Computed:    true,
+			},
+			"stdout_decoded": schema.DynamicAttribute{
+				MarkdownDescription: "The command's standard output decoded according to `output_format`. Objects are decoded into object values and arrays into tuple values, " +
+					"in the same way as the built-in Terraform decoding functions, and `dotenv` output is decoded into an object of strings. With `raw`, this is the same as `stdout`. " +
+					"`null` if `output_format` is not provided or the command returned no standard output.",
+				Computed: true,
+			},
 			"stderr": schema.StringAttribute{
 				Description: "Data returned from the command's standard error stream. The data is returned directly from the command as a UTF-8 string and will be " +
 					"populated regardless of the exit code returned.",
 				Computed: true,
 			},
+		}, dataSourceCommandAttributes(), dataSourceScriptAttributes(), dataSourceResultAttributes()),
+		Blocks: map[string]schema.Block{
+			"retry":   dataSourceRetryBlock(),
+			"limits":  dataSourceLimitsBlock(),
+			"sandbox": dataSourceSandboxBlock(),
 		},
 	}
 }
 
-type localCommandDataSourceModel struct {
-	Command              types.String `tfsdk:"command"`
-	Arguments            types.List   `tfsdk:"arguments"`
-	Stdin                types.String `tfsdk:"stdin"`
-	WorkingDirectory     types.String `tfsdk:"working_directory"`
-	AllowNonZeroExitCode types.Bool   `tfsdk:"allow_non_zero_exit_code"`
-	ExitCode             types.Int64  `tfsdk:"exit_code"`
-	Stdout               types.String `tfsdk:"stdout"`
-	Stderr               types.String `tfsdk:"stderr"`
-}
+func (a *localCommandDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var inheritEnvironment types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherit_environment"), &inheritEnvironment)...)
+
+	var inheritedEnvironmentVariables types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherited_environment_variables"), &inheritedEnvironmentVariables)...)
 
-func (a *localCommandDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
-	var state localCommandDataSourceModel
-	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Prep the command
-	command := state.Command.ValueString()
-	if _, err := exec.LookPath(command); err != nil {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Command Lookup Failed",
-			"The data source received an unexpected error while attempting to find the command."+
-				"\n\n"+
-				"The command must be accessible according to the platform where Terraform is running."+
-				"\n\n"+
-				"If the expected command should be automatically found on the platform where Terraform is running, "+
-				"ensure that the command is in an expected directory. On Unix-based platforms, these directories are "+
-				"typically searched based on the '$PATH' environment variable. On Windows-based platforms, these directories "+
-				"are typically searched based on the '%PATH%' environment variable."+
-				"\n\n"+
-				"If the expected command is relative to the Terraform configuration, it is recommended that the command name includes "+
-				"the interpolated value of 'path.module' before the command name to ensure that it is compatible with varying module usage. For example: \"${path.module}/my-command\""+
-				"\n\n"+
-				"The command must also be executable according to the platform where Terraform is running. On Unix-based platforms, the file on the filesystem must have the executable bit set. "+
-				"On Windows-based platforms, no action is typically necessary."+
-				"\n\n"+
-				fmt.Sprintf("Platform: %s\n", runtime.GOOS)+
-				fmt.Sprintf("Command: %s\n", command)+
-				fmt.Sprintf("Error: %s", err),
-		)
+	resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Empty(), inheritEnvironment, inheritedEnvironmentVariables)...)
+
+	var runAsUser, runAsGroup types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_user"), &runAsUser)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_group"), &runAsGroup)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	arguments := make([]string, 0)
-	for _, element := range state.Arguments.Elements() {
-		strElement, ok := element.(types.String)
-		// Mirroring the underlying os/exec Command support for args (no nil arguments, but does support empty strings)
-		if element.IsNull() || !ok {
-			continue
-		}
-
-		arguments = append(arguments, strElement.ValueString())
-	}
+	resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Empty(), runAsUser, runAsGroup)...)
 
-	cmd := exec.CommandContext(ctx, command, arguments...)
+	limits, diags := getConfigBlock[localCommandLimitsModel](ctx, req.Config, path.Root("limits"))
+	resp.Diagnostics.Append(diags...)
 
-	cmd.Dir = state.WorkingDirectory.ValueString()
+	sandbox, diags := getConfigBlock[localCommandSandboxModel](ctx, req.Config, path.Root("sandbox"))
+	resp.Diagnostics.Append(diags...)
 
-	if !state.Stdin.IsNull() {
-		cmd.Stdin = bytes.NewReader([]byte(state.Stdin.ValueString()))
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	var stdout strings.Builder
-	cmd.Stdout = &stdout
+	resp.Diagnostics.Append(localcommand.ValidateLimits(path.Empty(), limits.limits())...)
+	resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Empty(), sandbox.sandbox())...)
+}
 
-	tflog.Trace(ctx, "Executing local command", map[string]interface{}{"command": cmd.String()})
+type localCommandDataSourceModel struct {
+	Command                       types.String              `tfsdk:"command"`
+	Arguments                     types.List                `tfsdk:"arguments"`
+	Script                        types.String              `tfsdk:"script"`
+	Interpreter                   types.List                `tfsdk:"interpreter"`
+	Stdin                         types.String              `tfsdk:"stdin"`
+	StdinBase64                   types.String              `tfsdk:"stdin_base64"`
+	WorkingDirectory              types.String              `tfsdk:"working_directory"`
+	Environment                   types.Map                 `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                 `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String              `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String              `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String              `tfsdk:"run_as_group"`
+	MaxOutputBytes                types.Int64               `tfsdk:"max_output_bytes"`
+	OutputTruncation              types.String              `tfsdk:"output_truncation"`
+	Timeout                       localtypes.DurationValue  `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue  `tfsdk:"termination_grace_period"`
+	OutputFormat                  types.String              `tfsdk:"output_format"`
+	AllowNonZeroExitCode          types.Bool                `tfsdk:"allow_non_zero_exit_code"`
+	SuccessExitCodes              types.Set                 `tfsdk:"success_exit_codes"`
+	SuccessStdoutRegex            localtypes.RegexpValue    `tfsdk:"success_stdout_regex"`
+	SuccessStderrRegex            localtypes.RegexpValue    `tfsdk:"success_stderr_regex"`
+	FailureStdoutRegex            localtypes.RegexpValue    `tfsdk:"failure_stdout_regex"`
+	FailureStderrRegex            localtypes.RegexpValue    `tfsdk:"failure_stderr_regex"`
+	ExitCode                      types.Int64               `tfsdk:"exit_code"`
+	Stdout                        types.String              `tfsdk:"stdout"`
+	StdoutBase64                  types.String              `tfsdk:"stdout_base64"`
+	StdoutDecoded                 types.Dynamic             `tfsdk:"stdout_decoded"`
+	StdoutTruncated               types.Bool                `tfsdk:"stdout_truncated"`
+	Stderr                        types.String              `tfsdk:"stderr"`
+	StderrBase64                  types.String              `tfsdk:"stderr_base64"`
+	StderrTruncated               types.Bool                `tfsdk:"stderr_truncated"`
+	Retry                         *localCommandRetryModel   `tfsdk:"retry"`
+	Limits                        *localCommandLimitsModel  `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel `tfsdk:"sandbox"`
+}
 
-	// Run the command
-	commandErr := cmd.Run()
-	stdoutStr := stdout.String()
-	stderrStr := stderr.String()
+func (a *localCommandDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state localCommandDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if len(stderrStr) > 0 {
-		state.Stderr = types.StringValue(stderrStr)
+	stdin, diags := localcommand.Stdin(state.Stdin, state.StdinBase64)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if len(stdoutStr) > 0 {
-		state.Stdout = types.StringValue(stdoutStr)
+	// Prep the command
+	command := localcommand.Command{
+		Kind:                          localcommand.KindDataSource,
+		Name:                          state.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(state.Arguments),
+		Script:                        state.Script.ValueString(),
+		Interpreter:                   localcommand.Strings(state.Interpreter),
+		WorkingDirectory:              state.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(state.Environment),
+		SensitiveEnvironment:          localcommand.Environment(state.SensitiveEnvironment),
+		InheritEnvironment:            localcommand.InheritEnvironment(state.InheritEnvironment.ValueString()),
+		InheritedEnvironmentVariables: localcommand.Strings(state.InheritedEnvironmentVariables),
+		RunAs: localcommand.RunAs{
+			User:  state.RunAsUser.ValueString(),
+			Group: state.RunAsGroup.ValueString(),
+		},
+		Stdin:                  stdin,
+		MaxOutputBytes:         state.MaxOutputBytes.ValueInt64(),
+		OutputTruncation:       localcommand.OutputTruncation(state.OutputTruncation.ValueString()),
+		Timeout:                state.Timeout.ValueDuration(),
+		TerminationGracePeriod: state.TerminationGracePeriod.ValueDuration(),
+		AllowNonZeroExitCode:   state.AllowNonZeroExitCode.ValueBool(),
+		SuccessExitCodes:       localcommand.ExitCodes(state.SuccessExitCodes),
+		SuccessStdoutRegex:     state.SuccessStdoutRegex.ValueRegexp(),
+		SuccessStderrRegex:     state.SuccessStderrRegex.ValueRegexp(),
+		FailureStdoutRegex:     state.FailureStdoutRegex.ValueRegexp(),
+		FailureStderrRegex:     state.FailureStderrRegex.ValueRegexp(),
+		OutputFormat:           localcommand.OutputFormat(state.OutputFormat.ValueString()),
+		Retry:                  state.Retry.retry(),
+		Limits:                 state.Limits.limits(),
+		Sandbox:                state.Sandbox.sandbox(),
 	}
 
-	// ProcessState will always be populated if the command has been was successfully started (regardless of exit code)
-	if cmd.ProcessState != nil {
-		exitCode := cmd.ProcessState.ExitCode()
-		state.ExitCode = types.Int64Value(int64(exitCode))
+	name, attributePath := command.Executable()
+	resp.Diagnostics.Append(findCommand(a.providerData, attributePath, command.Kind, name, command.RunAs)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	tflog.Trace(ctx, "Executed local command", map[string]interface{}{"command": cmd.String(), "stdout": stdoutStr, "stderr": stderrStr})
+	// Run the command
+	result, diags := command.Run(ctx)
 
-	// Set all of the data to state
-	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
-	if commandErr == nil {
-		return
+	if len(result.Stderr) > 0 {
+		state.Stderr = types.StringValue(string(result.Stderr))
+		state.StderrBase64 = types.StringValue(base64.StdEncoding.EncodeToString(result.Stderr))
 	}
 
-	// If running the command returned an exit error, we need to check and see if we should explicitly raise a diagnostic
-	if exitError, ok := commandErr.(*exec.ExitError); ok {
-		// We won't return a diagnostic because the command was successfully started and then exited
-		// with a non-zero exit code (which the user has indicated they will handle in configuration).
-		//
-		// All data has already been saved to state, so we just return.
-		if state.AllowNonZeroExitCode.ValueBool() {
-			return
-		}
-
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Command Execution Failed",
-			"The data source executed the command but received a non-zero exit code. If a non-zero exit code is expected "+
-				"and can be handled in configuration, set \"allow_non_zero_exit_code\" to true."+
-				"\n\n"+
-				fmt.Sprintf("Command: %s\n", cmd.String())+
-				fmt.Sprintf("Command Error: %s\n", stderrStr)+
-				fmt.Sprintf("State: %s", exitError),
-		)
-		return
+	if len(result.Stdout) > 0 {
+		state.Stdout = types.StringValue(string(result.Stdout))
+		state.StdoutBase64 = types.StringValue(base64.StdEncoding.EncodeToString(result.Stdout))
 	}
 
-	// We need to raise a diagnostic because the command wasn't successfully started and we have no exit code.
-	resp.Diagnostics.AddAttributeError(
-		path.Root("command"),
-		"Command Execution Failed",
-		"The data source received an unexpected error while attempting to execute the command."+
-			"\n\n"+
-			fmt.Sprintf("Command: %s\n", cmd.String())+
-			fmt.Sprintf("State: %s", commandErr),
-	)
+	state.StdoutDecoded = result.StdoutDecoded
+	state.StdoutTruncated = types.BoolValue(result.StdoutTruncated)
+	state.StderrTruncated = types.BoolValue(result.StderrTruncated)
+
+	if result.Started {
+		state.ExitCode = types.Int64Value(int64(result.ExitCode))
+	}
+
+	// Set all of the data to state, before reporting any execution errors
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(diags...)
 }
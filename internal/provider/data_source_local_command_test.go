@@ -5,9 +5,11 @@ package provider
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -487,3 +489,662 @@ func TestLocalCommandDataSource_not_found(t *testing.T) {
 		},
 	})
 }
+
+// Test is dependent on: https://github.com/jqlang/jq
+func TestLocalCommandDataSource_allowed_commands(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `provider "local" {
+					allowed_commands = ["jq"]
+				}
+
+				data "local_command" "test" {
+					command   = "bash"
+					arguments = ["-c", "echo hello"]
+				}`,
+				ExpectError: regexp.MustCompile(`The command is not allowed by the allowed_commands provider configuration`),
+			},
+			{
+				Config: `provider "local" {
+					allowed_commands = ["j*"]
+					denied_commands  = ["jq"]
+				}
+
+				data "local_command" "test" {
+					command   = "jq"
+					stdin     = "{}"
+					arguments = ["."]
+				}`,
+				ExpectError: regexp.MustCompile(`The command is denied by the denied_commands provider configuration`),
+			},
+			{
+				Config: `provider "local" {
+					allowed_commands = ["jq"]
+				}
+
+				data "local_command" "test" {
+					command   = "jq"
+					stdin     = "{}"
+					arguments = ["-c", "."]
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(0)),
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("{}\n")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_timeout(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command                  = "sleep"
+					arguments                = ["30"]
+					timeout                  = "1s"
+					termination_grace_period = "5s"
+				}`,
+				ExpectError: regexp.MustCompile(`(?s)Command Timed Out.*The data source cancelled the command because it did not\s+complete within the timeout of 1s`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_invalid_timeout(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command = "sleep"
+					timeout = "30"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Duration String Value`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_environment(t *testing.T) {
+	t.Setenv("LOCAL_COMMAND_INHERITED", "inherited")
+	t.Setenv("LOCAL_COMMAND_NOT_INHERITED", "not inherited")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "all" {
+					command     = "sh"
+					arguments   = ["-c", "echo -n \"$LOCAL_COMMAND_INHERITED|$LOCAL_COMMAND_NOT_INHERITED|$LOCAL_COMMAND_SET\""]
+					environment = {
+						LOCAL_COMMAND_SET = "set"
+					}
+				}
+
+				data "local_command" "none" {
+					command             = "sh"
+					arguments           = ["-c", "echo -n \"$LOCAL_COMMAND_INHERITED|$LOCAL_COMMAND_NOT_INHERITED|$LOCAL_COMMAND_SET\""]
+					inherit_environment = "none"
+					environment = {
+						LOCAL_COMMAND_SET = "set"
+					}
+				}
+
+				data "local_command" "allowlist" {
+					command                         = "sh"
+					arguments                       = ["-c", "echo -n \"$LOCAL_COMMAND_INHERITED|$LOCAL_COMMAND_NOT_INHERITED|$LOCAL_COMMAND_SET\""]
+					inherit_environment             = "allowlist"
+					inherited_environment_variables = ["LOCAL_COMMAND_INHERITED"]
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.all", tfjsonpath.New("stdout"), knownvalue.StringExact("inherited|not inherited|set")),
+					statecheck.ExpectKnownValue("data.local_command.none", tfjsonpath.New("stdout"), knownvalue.StringExact("||set")),
+					statecheck.ExpectKnownValue("data.local_command.allowlist", tfjsonpath.New("stdout"), knownvalue.StringExact("inherited||")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_inherited_environment_variables_without_allowlist(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command                         = "sh"
+					inherit_environment             = "none"
+					inherited_environment_variables = ["HOME"]
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_sensitive_environment(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "echo -n \"$LOCAL_COMMAND_TOKEN\""]
+					sensitive_environment = {
+						LOCAL_COMMAND_TOKEN = "s3cr3t"
+					}
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("s3cr3t")),
+				},
+			},
+			{
+				Config: `data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "echo \"token $LOCAL_COMMAND_TOKEN rejected\" >&2; exit 1"]
+					sensitive_environment = {
+						LOCAL_COMMAND_TOKEN = "s3cr3t"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`Command Error: token \*\*\* rejected`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_output_format(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "json" {
+					command       = "echo"
+					arguments     = ["{\"name\": \"test\", \"items\": [1, 2]}"]
+					output_format = "json"
+				}
+
+				data "local_command" "yaml" {
+					command       = "printf"
+					arguments     = ["name: test\nenabled: true\n"]
+					output_format = "yaml"
+				}
+
+				data "local_command" "dotenv" {
+					command       = "printf"
+					arguments     = ["NAME=test\nexport OTHER='value'\n"]
+					output_format = "dotenv"
+				}
+
+				data "local_command" "none" {
+					command   = "echo"
+					arguments = ["{}"]
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.json", tfjsonpath.New("stdout_decoded"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"name":  knownvalue.StringExact("test"),
+						"items": knownvalue.TupleExact([]knownvalue.Check{knownvalue.Int64Exact(1), knownvalue.Int64Exact(2)}),
+					})),
+					statecheck.ExpectKnownValue("data.local_command.yaml", tfjsonpath.New("stdout_decoded"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"name":    knownvalue.StringExact("test"),
+						"enabled": knownvalue.Bool(true),
+					})),
+					statecheck.ExpectKnownValue("data.local_command.dotenv", tfjsonpath.New("stdout_decoded"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"NAME":  knownvalue.StringExact("test"),
+						"OTHER": knownvalue.StringExact("value"),
+					})),
+					statecheck.ExpectKnownValue("data.local_command.none", tfjsonpath.New("stdout_decoded"), knownvalue.Null()),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_output_format_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command       = "echo"
+					arguments     = ["not json"]
+					output_format = "json"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Command Output`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_base64(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				// The input is not valid UTF-8, so it is only preserved by the base64 attributes.
+				Config: `data "local_command" "test" {
+					command      = "sh"
+					arguments    = ["-c", "cat; cat >&2 <<EOF\nerr\nEOF"]
+					stdin_base64 = "AP8K"
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout_base64"), knownvalue.StringExact("AP8K")),
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stderr_base64"), knownvalue.StringExact("ZXJyCg==")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_stdin_conflicts(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command      = "cat"
+					stdin        = "hello"
+					stdin_base64 = "aGVsbG8="
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_stdin_base64_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command      = "cat"
+					stdin_base64 = "not base64!"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Base64 Value`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_max_output_bytes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "head" {
+					command           = "printf"
+					arguments         = ["0123456789"]
+					max_output_bytes  = 4
+					output_truncation = "truncate_head"
+				}
+
+				data "local_command" "tail" {
+					command           = "printf"
+					arguments         = ["0123456789"]
+					max_output_bytes  = 4
+					output_truncation = "truncate_tail"
+				}
+
+				data "local_command" "within" {
+					command          = "printf"
+					arguments        = ["0123456789"]
+					max_output_bytes = 10
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.head", tfjsonpath.New("stdout"), knownvalue.StringExact("6789")),
+					statecheck.ExpectKnownValue("data.local_command.head", tfjsonpath.New("stdout_truncated"), knownvalue.Bool(true)),
+					statecheck.ExpectKnownValue("data.local_command.tail", tfjsonpath.New("stdout"), knownvalue.StringExact("0123")),
+					statecheck.ExpectKnownValue("data.local_command.tail", tfjsonpath.New("stdout_truncated"), knownvalue.Bool(true)),
+					statecheck.ExpectKnownValue("data.local_command.within", tfjsonpath.New("stdout"), knownvalue.StringExact("0123456789")),
+					statecheck.ExpectKnownValue("data.local_command.within", tfjsonpath.New("stdout_truncated"), knownvalue.Bool(false)),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_max_output_bytes_error(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command          = "yes"
+					max_output_bytes = 1024
+				}`,
+				ExpectError: regexp.MustCompile(`Command Output Too Large`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_success_exit_codes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command            = "sh"
+					arguments          = ["-c", "echo changed; exit 2"]
+					success_exit_codes = [0, 2]
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(2)),
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("changed\n")),
+				},
+			},
+			{
+				Config: `data "local_command" "test" {
+					command            = "sh"
+					arguments          = ["-c", "exit 1"]
+					success_exit_codes = [0, 2]
+				}`,
+				ExpectError: regexp.MustCompile(`received exit code 1, which is not one of the "success_exit_codes"`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_success_exit_codes_conflicts(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command                  = "true"
+					success_exit_codes       = [0, 2]
+					allow_non_zero_exit_code = true
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_output_regex(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command              = "sh"
+					arguments            = ["-c", "echo 'already exists' >&2; exit 1"]
+					success_stderr_regex = "already exists"
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(1)),
+				},
+			},
+			{
+				Config: `data "local_command" "test" {
+					command              = "sh"
+					arguments            = ["-c", "echo 'ERROR: login failed'"]
+					failure_stdout_regex = "(?m)^ERROR:"
+				}`,
+				ExpectError: regexp.MustCompile(`its standard output matched "failure_stdout_regex"`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_output_regex_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command              = "true"
+					failure_stderr_regex = "(unclosed"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Regular Expression String Value`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_retry(t *testing.T) {
+	attemptsFile := filepath.Join(t.TempDir(), "attempts")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				// The command fails until it has been run three times.
+				Config: fmt.Sprintf(`data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "echo x >> \"$1\"; test $(wc -l < \"$1\") -ge 3 || { echo 'connection refused' >&2; exit 1; }", "retry", %q]
+
+					retry {
+						max_attempts          = 3
+						initial_delay         = "10ms"
+						retry_on_exit_codes   = [1]
+						retry_on_stderr_regex = "connection refused"
+					}
+				}`, attemptsFile),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(0)),
+				},
+			},
+			{
+				Config: `data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "exit 1"]
+
+					retry {
+						max_attempts  = 2
+						initial_delay = "10ms"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The command was run 2 times and failed every attempt`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_script(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					script    = <<-EOT
+						echo "hello, $1"
+						test -x "$0" && echo "executable"
+					EOT
+					arguments = ["world"]
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("hello, world\nexecutable\n")),
+				},
+			},
+			{
+				Config: `data "local_command" "test" {
+					interpreter = ["bash", "-eu"]
+					script      = "echo \"$${BASH_VERSION:+bash}\""
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("bash\n")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_script_interpreter_not_allowed(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `provider "local" {
+					allowed_commands = ["/bin/sh"]
+				}
+
+				data "local_command" "test" {
+					interpreter = ["bash"]
+					script      = "echo hello"
+				}`,
+				ExpectError: regexp.MustCompile(`The command is not allowed by the allowed_commands provider configuration`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_command_and_script(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command = "echo"
+					script  = "echo hello"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_run_as_user(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("running a command as a different user is only supported on Linux")
+	}
+
+	if os.Getuid() != 0 {
+		t.Skip("running a command as a different user requires root")
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command      = "id"
+					arguments    = ["-u"]
+					run_as_user  = "4321"
+					run_as_group = "4321"
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("4321\n")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_run_as_unknown_user(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("running a command as a different user is only supported on Linux")
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command     = "id"
+					run_as_user = "terraform-provider-local-unknown"
+				}`,
+				ExpectError: regexp.MustCompile(`unable to look up user terraform-provider-local-unknown`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_limits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("limits are only supported on Linux")
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "ulimit -n; nice"]
+
+					limits {
+						max_open_files = 64
+						nice           = 5
+					}
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("64\n5\n")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_limits_max_cpu_seconds(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("limits are only supported on Linux")
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `data "local_command" "test" {
+					command   = "sh"
+					arguments = ["-c", "while :; do :; done"]
+
+					limits {
+						max_cpu_seconds = 1
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`exceeding the "max_cpu_seconds" limit`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandDataSource_sandbox(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sandboxes are only supported on Linux")
+	}
+
+	allowedDir := t.TempDir()
+	deniedDir := t.TempDir()
+
+	for _, dir := range []string{allowedDir, deniedDir} {
+		if err := os.WriteFile(filepath.Join(dir, "file"), []byte("content"), 0600); err != nil {
+			t.Fatalf("unable to write file: %s", err)
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`data "local_command" "test" {
+					command   = "cat"
+					arguments = [%[1]q]
+
+					sandbox {
+						read_only_paths = ["/usr", "/lib", "/etc", %[2]q]
+					}
+				}`, filepath.Join(allowedDir, "file"), allowedDir),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("content")),
+				},
+			},
+			{
+				Config: fmt.Sprintf(`data "local_command" "test" {
+					command   = "cat"
+					arguments = [%[1]q]
+
+					sandbox {
+						read_only_paths = ["/usr", "/lib", "/etc", %[2]q]
+					}
+				}`, filepath.Join(deniedDir, "file"), allowedDir),
+				ExpectError: regexp.MustCompile(`Permission denied`),
+			},
+		},
+	})
+}
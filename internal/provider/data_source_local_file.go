@@ -11,18 +11,39 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
-	_ datasource.DataSource = (*localFileDataSource)(nil)
+	_ datasource.DataSource              = (*localFileDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*localFileDataSource)(nil)
 )
 
 func NewLocalFileDataSource() datasource.DataSource {
 	return &localFileDataSource{}
 }
 
-type localFileDataSource struct{}
+type localFileDataSource struct {
+	providerData *localProviderData
+}
+
+func (n *localFileDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	n.providerData = providerData
+}
 
 func (n *localFileDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -87,6 +108,11 @@ func (n *localFileDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 
+	resp.Diagnostics.Append(n.providerData.checkPath(path.Root("filename"), config.Filename.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read the entire file content
 	filepath := config.Filename.ValueString()
 	content, err := os.ReadFile(filepath)
@@ -5,6 +5,10 @@ package provider
 
 import (
 	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/config"
@@ -34,3 +38,42 @@ func TestLocalFileDataSource(t *testing.T) {
 		},
 	})
 }
+
+func TestLocalFileDataSource_AllowedPaths(t *testing.T) {
+	allowedDirPath := t.TempDir()
+	allowedDirPath = strings.ReplaceAll(allowedDirPath, `\`, `\\`)
+	fileDirPath, err := filepath.Abs("testdata/TestLocalFileDataSource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(fileDirPath, "local_file")
+	filename = strings.ReplaceAll(filename, `\`, `\\`)
+	fileDirPath = strings.ReplaceAll(fileDirPath, `\`, `\\`)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigProviderAllowedPaths(allowedDirPath) + fmt.Sprintf(`
+				data "local_file" "file" {
+				  filename = "%s"
+				}`, filename),
+				ExpectError: regexp.MustCompile(`Path Not Allowed`),
+			},
+			{
+				Config: testAccConfigProviderAllowedPaths(allowedDirPath) + fmt.Sprintf(`
+				data "local_sensitive_file" "file" {
+				  filename = "%s"
+				}`, filename),
+				ExpectError: regexp.MustCompile(`Path Not Allowed`),
+			},
+			{
+				Config: testAccConfigProviderAllowedPaths(fileDirPath) + fmt.Sprintf(`
+				data "local_file" "file" {
+				  filename = "%s"
+				}`, filename),
+				Check: resource.TestCheckResourceAttr("data.local_file.file", "content", "This is some content"),
+			},
+		},
+	})
+}
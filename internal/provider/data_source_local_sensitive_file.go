@@ -5,13 +5,15 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 )
 
 var (
-	_ datasource.DataSource = (*localSensitiveFileDataSource)(nil)
+	_ datasource.DataSource              = (*localSensitiveFileDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*localSensitiveFileDataSource)(nil)
 )
 
 func NewLocalSensitiveFileDataSourceWithSchema() datasource.DataSource {
@@ -22,7 +24,26 @@ func NewLocalSensitiveFileDataSource() datasource.DataSource {
 	return &localSensitiveFileDataSource{}
 }
 
-type localSensitiveFileDataSource struct{}
+type localSensitiveFileDataSource struct {
+	providerData *localProviderData
+}
+
+func (n *localSensitiveFileDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	n.providerData = providerData
+}
 
 func (n *localSensitiveFileDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_sensitive_file"
@@ -84,5 +105,5 @@ func (n *localSensitiveFileDataSource) Read(ctx context.Context, req datasource.
 	// all this data source does, is adding "Sensitive: true" to the schema of the property.
 	//
 	// The values and the property names are meant to be kept the same between data sources.
-	NewLocalFileDataSource().Read(ctx, req, resp)
+	(&localFileDataSource{providerData: n.providerData}).Read(ctx, req, resp)
 }
@@ -4,27 +4,58 @@
 package provider
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"os/exec"
-	"runtime"
-	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
 )
 
-var _ ephemeral.EphemeralResource = (*localCommandEphemeral)(nil)
+var (
+	_ ephemeral.EphemeralResource                   = (*localCommandEphemeral)(nil)
+	_ ephemeral.EphemeralResourceWithConfigure      = (*localCommandEphemeral)(nil)
+	_ ephemeral.EphemeralResourceWithValidateConfig = (*localCommandEphemeral)(nil)
+	_ ephemeral.EphemeralResourceWithClose          = (*localCommandEphemeral)(nil)
+	_ ephemeral.EphemeralResourceWithRenew          = (*localCommandEphemeral)(nil)
+)
 
 func NewLocalCommandEphemeral() ephemeral.EphemeralResource {
 	return &localCommandEphemeral{}
 }
 
-type localCommandEphemeral struct{}
+type localCommandEphemeral struct {
+	providerData *localProviderData
+}
+
+func (e *localCommandEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	e.providerData = providerData
+}
 
 func (e *localCommandEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_command"
@@ -33,13 +64,20 @@ func (e *localCommandEphemeral) Metadata(ctx context.Context, req ephemeral.Meta
 func (e *localCommandEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Runs an executable on the local machine and returns the exit code, standard output data (`stdout`), and standard error data (`stderr`). " +
-			"All environment variables visible to the Terraform process are passed through to the child process. Both `stdout` and `stderr` returned by this ephemeral resource " +
+			"All environment variables visible to the Terraform process are passed through to the child process, unless limited with `inherit_environment`. " +
+			"Additional environment variables can be explicitly set via the `environment` attribute. Both `stdout` and `stderr` returned by this ephemeral resource " +
 			"are UTF-8 strings, which can be decoded into [Terraform values](https://developer.hashicorp.com/terraform/language/expressions/types) for use elsewhere in the Terraform configuration. " +
 			"There are built-in decoding functions such as [`jsondecode`](https://developer.hashicorp.com/terraform/language/functions/jsondecode) or [`yamldecode`](https://developer.hashicorp.com/terraform/language/functions/yamldecode), " +
-			"and more specialized [decoding functions](https://developer.hashicorp.com/terraform/plugin/framework/functions/concepts) can be built with a Terraform provider." +
+			"and more specialized [decoding functions](https://developer.hashicorp.com/terraform/plugin/framework/functions/concepts) can be built with a Terraform provider. " +
+			"Alternatively, set `output_format` to decode `stdout` into `stdout_decoded`, so that decoding errors are reported on the command itself." +
 			"\n\n" +
 			"Any non-zero exit code returned by the command will be treated as an error and will return a diagnostic to Terraform containing the `stderr` message if available. " +
-			"If a non-zero exit code is expected by the command, set `allow_non_zero_exit_code` to `true`." +
+			"If a non-zero exit code is expected by the command, set `allow_non_zero_exit_code` to `true`, or list the exit codes that indicate success in `success_exit_codes`. " +
+			"Regular expressions matching the command's output can also indicate success or failure regardless of the exit code, with `success_stdout_regex`, " +
+			"`success_stderr_regex`, `failure_stdout_regex` and `failure_stderr_regex`." +
+			"\n\n" +
+			"Anything acquired by the command, such as a temporary credential or a lease, can be released with the `close_command` block once Terraform no longer needs it, " +
+			"and kept alive with the `renew_command` block every `renew_interval`. Both commands are passed the standard output of the command on their standard input." +
 			"\n\n" +
 			"~> **Warning** This mechanism is provided as an \"escape hatch\" for exceptional situations where a first-class Terraform provider is not more appropriate. " +
 			"Its capabilities are limited in comparison to a true ephemeral resource, and implementing an ephemeral resource via a local executable is likely to hurt the " +
@@ -48,29 +86,29 @@ func (e *localCommandEphemeral) Schema(ctx context.Context, req ephemeral.Schema
 			"\n\n" +
 			"~> **Warning** HCP Terraform and Terraform Enterprise do not guarantee availability of any particular language runtimes or external programs beyond standard shell utilities, " +
 			"so it is not recommended to use this ephemeral resource within configurations that are applied within either.",
-		Attributes: map[string]schema.Attribute{
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
 			"command": schema.StringAttribute{
-				Description: "Executable name to be discovered on the PATH or absolute path to executable.",
-				Required:    true,
-			},
-			"arguments": schema.ListAttribute{
-				MarkdownDescription: "Arguments to be passed to the given command. Any `null` arguments will be removed from the list.",
-				ElementType:         types.StringType,
+				MarkdownDescription: "Executable name to be discovered on the PATH or absolute path to executable. Exactly one of `command` or `script` must be set.",
 				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("command"), path.MatchRoot("script")),
+				},
 			},
 			"stdin": schema.StringAttribute{
 				MarkdownDescription: "Data to be passed to the given command's standard input as a UTF-8 string. [Terraform values](https://developer.hashicorp.com/terraform/language/expressions/types) can be encoded " +
 					"by any Terraform encode function, for example, [`jsonencode`](https://developer.hashicorp.com/terraform/language/functions/jsonencode).",
 				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("stdin_base64")),
+				},
 			},
-			"working_directory": schema.StringAttribute{
-				Description: "The directory path where the command should be executed, either an absolute path or relative to the Terraform working directory. If not provided, defaults to the Terraform working directory.",
-				Optional:    true,
-			},
-			"allow_non_zero_exit_code": schema.BoolAttribute{
-				MarkdownDescription: "Indicates that the command returning a non-zero exit code should be treated as a successful execution. " +
-					"Further assertions can be made of the `exit_code` value with the [`check` block](https://developer.hashicorp.com/terraform/language/block/check). Defaults to false.",
+			"output_format": schema.StringAttribute{
+				MarkdownDescription: "The format used to decode the command's standard output into `stdout_decoded`. Valid values are `json`, `yaml`, `toml`, `dotenv` and `raw`. " +
+					"If the output cannot be decoded, the ephemeral resource returns a diagnostic to Terraform. If not provided, `stdout_decoded` is `null`.",
 				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(localcommand.OutputFormats()...),
+				},
 			},
 			"exit_code": schema.Int64Attribute{
 				MarkdownDescription: "The exit code returned by the command. By default, if the exit code is non-zero, the ephemeral resource will return a diagnostic to Terraform. " +
@@ -82,24 +120,203 @@ func (e *localCommandEphemeral) Schema(ctx context.Context, req ephemeral.Schema
 					"which can then be decoded by any Terraform decode function, for example, [`jsondecode`](https://developer.hashicorp.com/terraform/language/functions/jsondecode).",
 				Computed: true,
 			},
+			"stdout_base64": schema.StringAttribute{
+				MarkdownDescription: "Data returned from the command's standard output stream, encoded as a base64 string. Unlike `stdout`, this preserves output that is not valid UTF-8, " +
+					"such as generated keys or archives, and can be decoded with [`base64decode`](https://developer.hashicorp.com/terraform/language/functions/base64decode) or passed to `content_base64` of `local_file`.",
+				Computed: true,
+			},
+			"stderr_base64": schema.StringAttribute{
+				Description: "Data returned from the command's standard error stream, encoded as a base64 string. This preserves output that is not valid UTF-8 and will be " +
+					"populated regardless of the exit code returned.",
+				Computed: true,
+			},
+			"stdout_truncated": schema.BoolAttribute{
+				Description: "Whether output was discarded from the command's standard output stream because it exceeded `max_output_bytes`.",
+				Computed:    true,
+			},
+			"stderr_truncated": schema.BoolAttribute{
+				Description: "Whether output was discarded from the command's standard error stream because it exceeded `max_output_bytes`.",
+				Computed:    true,
+			},
+			"stdout_decoded": schema.DynamicAttribute{
+				MarkdownDescription: "The command's standard output decoded according to `output_format`. Objects are decoded into object values and arrays into tuple values, " +
+					"in the same way as the built-in Terraform decoding functions, and `dotenv` output is decoded into an object of strings. With `raw`, this is the same as `stdout`. " +
+					"`null` if `output_format` is not provided or the command returned no standard output.",
+				Computed: true,
+			},
 			"stderr": schema.StringAttribute{
 				Description: "Data returned from the command's standard error stream. The data is returned directly from the command as a UTF-8 string and will be " +
 					"populated regardless of the exit code returned.",
 				Computed: true,
 			},
+			"renew_interval": schema.StringAttribute{
+				MarkdownDescription: "How often the `renew_command` is run while Terraform is using the ephemeral resource, as a duration string such as `5m`. " +
+					"Must be set together with `renew_command`.",
+				CustomType: localtypes.NewDurationType(),
+				Optional:   true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("renew_command")),
+				},
+			},
+		}, ephemeralCommandAttributes(), ephemeralScriptAttributes(), ephemeralResultAttributes()),
+		Blocks: map[string]schema.Block{
+			"close_command": localCommandEphemeralHookBlock(
+				"The command that is run when Terraform no longer needs the ephemeral resource, such as to revoke a temporary credential. " +
+					"Any non-zero exit code returned by the command will be treated as an error.",
+			),
+			"renew_command": localCommandEphemeralHookBlock(
+				"The command that is run every `renew_interval` while Terraform is using the ephemeral resource, such as to extend a lease. "+
+					"Any non-zero exit code returned by the command will be treated as an error. Must be set together with `renew_interval`.",
+				objectvalidator.AlsoRequires(path.MatchRoot("renew_interval")),
+			),
+			"limits":  ephemeralLimitsBlock(),
+			"sandbox": ephemeralSandboxBlock(),
 		},
 	}
 }
 
+// localCommandEphemeralHookBlock returns the schema of a block that configures
+// a command run after the ephemeral resource is opened.
+func localCommandEphemeralHookBlock(description string, validators ...validator.Object) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: description,
+		Validators:          validators,
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
+			"command": schema.StringAttribute{
+				Description: "Executable name to be discovered on the PATH or absolute path to executable. Must be set when the block is configured.",
+				Optional:    true,
+			},
+		}, ephemeralCommandAttributes()),
+		Blocks: map[string]schema.Block{
+			"limits":  ephemeralLimitsBlock(),
+			"sandbox": ephemeralSandboxBlock(),
+		},
+	}
+}
+
+func (e *localCommandEphemeral) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var inheritEnvironment types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherit_environment"), &inheritEnvironment)...)
+
+	var inheritedEnvironmentVariables types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherited_environment_variables"), &inheritedEnvironmentVariables)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Empty(), inheritEnvironment, inheritedEnvironmentVariables)...)
+
+	var runAsUser, runAsGroup types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_user"), &runAsUser)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_group"), &runAsGroup)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Empty(), runAsUser, runAsGroup)...)
+
+	for _, name := range []string{"close_command", "renew_command"} {
+		var block types.Object
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), &block)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if block.IsNull() || block.IsUnknown() {
+			continue
+		}
+
+		var model localCommandEphemeralHookModel
+		resp.Diagnostics.Append(block.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// The command is optional in the schema, as attributes of an absent
+		// block cannot be required, so it is required here instead.
+		if model.Command.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name).AtName("command"),
+				"Missing Attribute Configuration",
+				fmt.Sprintf("The \"command\" attribute must be configured in the %q block.", name),
+			)
+		}
+
+		resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Root(name), model.InheritEnvironment, model.InheritedEnvironmentVariables)...)
+		resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Root(name), model.RunAsUser, model.RunAsGroup)...)
+		resp.Diagnostics.Append(localcommand.ValidateLimits(path.Root(name), model.Limits.limits())...)
+		resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Root(name), model.Sandbox.sandbox())...)
+	}
+
+	limits, diags := getConfigBlock[localCommandLimitsModel](ctx, req.Config, path.Root("limits"))
+	resp.Diagnostics.Append(diags...)
+
+	sandbox, diags := getConfigBlock[localCommandSandboxModel](ctx, req.Config, path.Root("sandbox"))
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateLimits(path.Empty(), limits.limits())...)
+	resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Empty(), sandbox.sandbox())...)
+}
+
 type localCommandEphemeralModel struct {
-	Command              types.String `tfsdk:"command"`
-	Arguments            types.List   `tfsdk:"arguments"`
-	Stdin                types.String `tfsdk:"stdin"`
-	WorkingDirectory     types.String `tfsdk:"working_directory"`
-	AllowNonZeroExitCode types.Bool   `tfsdk:"allow_non_zero_exit_code"`
-	ExitCode             types.Int64  `tfsdk:"exit_code"`
-	Stdout               types.String `tfsdk:"stdout"`
-	Stderr               types.String `tfsdk:"stderr"`
+	Command                       types.String              `tfsdk:"command"`
+	Arguments                     types.List                `tfsdk:"arguments"`
+	Script                        types.String              `tfsdk:"script"`
+	Interpreter                   types.List                `tfsdk:"interpreter"`
+	Stdin                         types.String              `tfsdk:"stdin"`
+	StdinBase64                   types.String              `tfsdk:"stdin_base64"`
+	WorkingDirectory              types.String              `tfsdk:"working_directory"`
+	Environment                   types.Map                 `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                 `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String              `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String              `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String              `tfsdk:"run_as_group"`
+	MaxOutputBytes                types.Int64               `tfsdk:"max_output_bytes"`
+	OutputTruncation              types.String              `tfsdk:"output_truncation"`
+	Timeout                       localtypes.DurationValue  `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue  `tfsdk:"termination_grace_period"`
+	OutputFormat                  types.String              `tfsdk:"output_format"`
+	AllowNonZeroExitCode          types.Bool                `tfsdk:"allow_non_zero_exit_code"`
+	SuccessExitCodes              types.Set                 `tfsdk:"success_exit_codes"`
+	SuccessStdoutRegex            localtypes.RegexpValue    `tfsdk:"success_stdout_regex"`
+	SuccessStderrRegex            localtypes.RegexpValue    `tfsdk:"success_stderr_regex"`
+	FailureStdoutRegex            localtypes.RegexpValue    `tfsdk:"failure_stdout_regex"`
+	FailureStderrRegex            localtypes.RegexpValue    `tfsdk:"failure_stderr_regex"`
+	ExitCode                      types.Int64               `tfsdk:"exit_code"`
+	Stdout                        types.String              `tfsdk:"stdout"`
+	StdoutBase64                  types.String              `tfsdk:"stdout_base64"`
+	StdoutDecoded                 types.Dynamic             `tfsdk:"stdout_decoded"`
+	StdoutTruncated               types.Bool                `tfsdk:"stdout_truncated"`
+	Stderr                        types.String              `tfsdk:"stderr"`
+	StderrBase64                  types.String              `tfsdk:"stderr_base64"`
+	StderrTruncated               types.Bool                `tfsdk:"stderr_truncated"`
+	RenewInterval                 localtypes.DurationValue  `tfsdk:"renew_interval"`
+	CloseCommand                  types.Object              `tfsdk:"close_command"`
+	RenewCommand                  types.Object              `tfsdk:"renew_command"`
+	Limits                        *localCommandLimitsModel  `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel `tfsdk:"sandbox"`
+}
+
+type localCommandEphemeralHookModel struct {
+	Command                       types.String              `tfsdk:"command"`
+	Arguments                     types.List                `tfsdk:"arguments"`
+	WorkingDirectory              types.String              `tfsdk:"working_directory"`
+	Timeout                       localtypes.DurationValue  `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue  `tfsdk:"termination_grace_period"`
+	Environment                   types.Map                 `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                 `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String              `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String              `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String              `tfsdk:"run_as_group"`
+	Limits                        *localCommandLimitsModel  `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel `tfsdk:"sandbox"`
 }
 
 func (e *localCommandEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
@@ -109,117 +326,277 @@ func (e *localCommandEphemeral) Open(ctx context.Context, req ephemeral.OpenRequ
 		return
 	}
 
+	stdin, diags := localcommand.Stdin(state.Stdin, state.StdinBase64)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Prep the command
-	command := state.Command.ValueString()
-	if _, err := exec.LookPath(command); err != nil {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Command Lookup Failed",
-			"The ephemeral resource received an unexpected error while attempting to find the command."+
-				"\n\n"+
-				"The command must be accessible according to the platform where Terraform is running."+
-				"\n\n"+
-				"If the expected command should be automatically found on the platform where Terraform is running, "+
-				"ensure that the command is in an expected directory. On Unix-based platforms, these directories are "+
-				"typically searched based on the '$PATH' environment variable. On Windows-based platforms, these directories "+
-				"are typically searched based on the '%PATH%' environment variable."+
-				"\n\n"+
-				"If the expected command is relative to the Terraform configuration, it is recommended that the command name includes "+
-				"the interpolated value of 'path.module' before the command name to ensure that it is compatible with varying module usage. For example: \"${path.module}/my-command\""+
-				"\n\n"+
-				"The command must also be executable according to the platform where Terraform is running. On Unix-based platforms, the file on the filesystem must have the executable bit set. "+
-				"On Windows-based platforms, no action is typically necessary."+
-				"\n\n"+
-				fmt.Sprintf("Platform: %s\n", runtime.GOOS)+
-				fmt.Sprintf("Command: %s\n", command)+
-				fmt.Sprintf("Error: %s", err),
-		)
+	command := localcommand.Command{
+		Kind:                          localcommand.KindEphemeralResource,
+		Name:                          state.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(state.Arguments),
+		Script:                        state.Script.ValueString(),
+		Interpreter:                   localcommand.Strings(state.Interpreter),
+		WorkingDirectory:              state.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(state.Environment),
+		SensitiveEnvironment:          localcommand.Environment(state.SensitiveEnvironment),
+		InheritEnvironment:            localcommand.InheritEnvironment(state.InheritEnvironment.ValueString()),
+		InheritedEnvironmentVariables: localcommand.Strings(state.InheritedEnvironmentVariables),
+		RunAs: localcommand.RunAs{
+			User:  state.RunAsUser.ValueString(),
+			Group: state.RunAsGroup.ValueString(),
+		},
+		Limits:                 state.Limits.limits(),
+		Sandbox:                state.Sandbox.sandbox(),
+		Stdin:                  stdin,
+		MaxOutputBytes:         state.MaxOutputBytes.ValueInt64(),
+		OutputTruncation:       localcommand.OutputTruncation(state.OutputTruncation.ValueString()),
+		Timeout:                state.Timeout.ValueDuration(),
+		TerminationGracePeriod: state.TerminationGracePeriod.ValueDuration(),
+		AllowNonZeroExitCode:   state.AllowNonZeroExitCode.ValueBool(),
+		SuccessExitCodes:       localcommand.ExitCodes(state.SuccessExitCodes),
+		SuccessStdoutRegex:     state.SuccessStdoutRegex.ValueRegexp(),
+		SuccessStderrRegex:     state.SuccessStderrRegex.ValueRegexp(),
+		FailureStdoutRegex:     state.FailureStdoutRegex.ValueRegexp(),
+		FailureStderrRegex:     state.FailureStderrRegex.ValueRegexp(),
+		OutputFormat:           localcommand.OutputFormat(state.OutputFormat.ValueString()),
+	}
+
+	name, attributePath := command.Executable()
+	resp.Diagnostics.Append(findCommand(e.providerData, attributePath, command.Kind, name, command.RunAs)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	arguments := make([]string, 0)
-	for _, element := range state.Arguments.Elements() {
-		strElement, ok := element.(types.String)
-		// Mirroring the underlying os/exec Command support for args (no nil arguments, but does support empty strings)
-		if element.IsNull() || !ok {
-			continue
+	// The close and renew commands are not configured in their requests, so
+	// they are passed along with the output of the command as private data.
+	private := localCommandEphemeralPrivate{
+		RenewInterval: state.RenewInterval.ValueDuration(),
+	}
+
+	private.Close, diags = newLocalCommandEphemeralHook(ctx, path.Root("close_command"), state.CloseCommand)
+	resp.Diagnostics.Append(diags...)
+
+	private.Renew, diags = newLocalCommandEphemeralHook(ctx, path.Root("renew_command"), state.RenewCommand)
+	resp.Diagnostics.Append(diags...)
+
+	for _, hook := range []*localCommandEphemeralHook{private.Close, private.Renew} {
+		if hook != nil {
+			resp.Diagnostics.Append(findCommand(e.providerData, hook.Path().AtName("command"), command.Kind, hook.Name, hook.runAs())...)
 		}
+	}
 
-		arguments = append(arguments, strElement.ValueString())
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	cmd := exec.CommandContext(ctx, command, arguments...)
+	// Run the command
+	result, diags := command.Run(ctx)
 
-	cmd.Dir = state.WorkingDirectory.ValueString()
+	if len(result.Stderr) > 0 {
+		state.Stderr = types.StringValue(string(result.Stderr))
+		state.StderrBase64 = types.StringValue(base64.StdEncoding.EncodeToString(result.Stderr))
+	}
 
-	if !state.Stdin.IsNull() {
-		cmd.Stdin = bytes.NewReader([]byte(state.Stdin.ValueString()))
+	if len(result.Stdout) > 0 {
+		state.Stdout = types.StringValue(string(result.Stdout))
+		state.StdoutBase64 = types.StringValue(base64.StdEncoding.EncodeToString(result.Stdout))
 	}
 
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	var stdout strings.Builder
-	cmd.Stdout = &stdout
+	state.StdoutDecoded = result.StdoutDecoded
+	state.StdoutTruncated = types.BoolValue(result.StdoutTruncated)
+	state.StderrTruncated = types.BoolValue(result.StderrTruncated)
 
-	tflog.Trace(ctx, "Executing local command", map[string]interface{}{"command": cmd.String()})
+	if result.Started {
+		state.ExitCode = types.Int64Value(int64(result.ExitCode))
+	}
 
-	// Run the command
-	commandErr := cmd.Run()
-	stdoutStr := stdout.String()
-	stderrStr := stderr.String()
+	// Set all of the data to result, before reporting any execution errors
+	resp.Diagnostics.Append(resp.Result.Set(ctx, state)...)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || (private.Close == nil && private.Renew == nil) {
+		return
+	}
+
+	private.Stdout = result.Stdout
 
-	if len(stderrStr) > 0 {
-		state.Stderr = types.StringValue(stderrStr)
+	data, err := json.Marshal(private)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Open local command error",
+			"An unexpected error occurred while storing the close and renew commands\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return
 	}
 
-	if len(stdoutStr) > 0 {
-		state.Stdout = types.StringValue(stdoutStr)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, localCommandEphemeralPrivateKey, data)...)
+
+	if private.Renew != nil {
+		resp.RenewAt = time.Now().Add(private.RenewInterval)
 	}
+}
 
-	// ProcessState will always be populated if the command has been was successfully started (regardless of exit code)
-	if cmd.ProcessState != nil {
-		exitCode := cmd.ProcessState.ExitCode()
-		state.ExitCode = types.Int64Value(int64(exitCode))
+func (e *localCommandEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	private, diags := getLocalCommandEphemeralPrivate(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || private == nil || private.Renew == nil {
+		return
 	}
 
-	tflog.Trace(ctx, "Executed local command", map[string]interface{}{"command": cmd.String(), "stdout": stdoutStr, "stderr": stderrStr})
+	resp.Diagnostics.Append(e.runHook(ctx, private.Renew, private.Stdout)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Set all of the data to result
-	resp.Diagnostics.Append(resp.Result.Set(ctx, state)...)
-	if commandErr == nil {
+	resp.RenewAt = time.Now().Add(private.RenewInterval)
+}
+
+func (e *localCommandEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	private, diags := getLocalCommandEphemeralPrivate(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || private == nil || private.Close == nil {
 		return
 	}
 
-	// If running the command returned an exit error, we need to check and see if we should explicitly raise a diagnostic
-	if exitError, ok := commandErr.(*exec.ExitError); ok {
-		// We won't return a diagnostic because the command was successfully started and then exited
-		// with a non-zero exit code (which the user has indicated they will handle in configuration).
-		//
-		// All data has already been saved to result, so we just return.
-		if state.AllowNonZeroExitCode.ValueBool() {
-			return
-		}
+	resp.Diagnostics.Append(e.runHook(ctx, private.Close, private.Stdout)...)
+}
+
+// runHook verifies that the close or renew command is allowed by the provider
+// configuration and then runs it, passing stdout on its standard input.
+func (e *localCommandEphemeral) runHook(ctx context.Context, hook *localCommandEphemeralHook, stdout []byte) diag.Diagnostics {
+	command := hook.command(stdout)
+
+	diags := findCommand(e.providerData, hook.Path().AtName("command"), command.Kind, command.Name, command.RunAs)
+	if diags.HasError() {
+		return diags
+	}
+
+	_, runDiags := command.Run(ctx)
+	diags.Append(runDiags...)
+
+	return diags
+}
+
+// localCommandEphemeralPrivateKey is the private data key holding the
+// localCommandEphemeralPrivate of an opened ephemeral resource.
+const localCommandEphemeralPrivateKey = "command"
+
+// localCommandEphemeralPrivate is the private data passed from Open to Renew
+// and Close.
+type localCommandEphemeralPrivate struct {
+	Stdout        []byte                     `json:"stdout,omitempty"`
+	Close         *localCommandEphemeralHook `json:"close,omitempty"`
+	Renew         *localCommandEphemeralHook `json:"renew,omitempty"`
+	RenewInterval time.Duration              `json:"renew_interval,omitempty"`
+}
+
+// privateStateReader is implemented by the private data of the ephemeral
+// resource requests.
+type privateStateReader interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+func getLocalCommandEphemeralPrivate(ctx context.Context, privateState privateStateReader) (*localCommandEphemeralPrivate, diag.Diagnostics) {
+	data, diags := privateState.GetKey(ctx, localCommandEphemeralPrivateKey)
+	if diags.HasError() || data == nil {
+		return nil, diags
+	}
 
-		resp.Diagnostics.AddAttributeError(
-			path.Root("command"),
-			"Command Execution Failed",
-			"The ephemeral resource executed the command but received a non-zero exit code. If a non-zero exit code is expected "+
-				"and can be handled in configuration, set \"allow_non_zero_exit_code\" to true."+
-				"\n\n"+
-				fmt.Sprintf("Command: %s\n", cmd.String())+
-				fmt.Sprintf("Command Error: %s\n", stderrStr)+
-				fmt.Sprintf("State: %s", exitError),
+	var private localCommandEphemeralPrivate
+	if err := json.Unmarshal(data, &private); err != nil {
+		diags.AddError(
+			"Unexpected Private Data",
+			"The ephemeral resource was unable to read the close and renew commands from its private data. Please report this issue to the provider developers.\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
 		)
-		return
+		return nil, diags
+	}
+
+	return &private, diags
+}
+
+// localCommandEphemeralHook is a close or renew command, as stored in private
+// data.
+type localCommandEphemeralHook struct {
+	Block                         string                `json:"block"`
+	Name                          string                `json:"name"`
+	Arguments                     []string              `json:"arguments,omitempty"`
+	WorkingDirectory              string                `json:"working_directory,omitempty"`
+	Environment                   map[string]string     `json:"environment,omitempty"`
+	SensitiveEnvironment          map[string]string     `json:"sensitive_environment,omitempty"`
+	InheritEnvironment            string                `json:"inherit_environment,omitempty"`
+	InheritedEnvironmentVariables []string              `json:"inherited_environment_variables,omitempty"`
+	RunAsUser                     string                `json:"run_as_user,omitempty"`
+	RunAsGroup                    string                `json:"run_as_group,omitempty"`
+	Limits                        *localcommand.Limits  `json:"limits,omitempty"`
+	Sandbox                       *localcommand.Sandbox `json:"sandbox,omitempty"`
+	Timeout                       time.Duration         `json:"timeout,omitempty"`
+	TerminationGracePeriod        time.Duration         `json:"termination_grace_period,omitempty"`
+}
+
+// newLocalCommandEphemeralHook returns the command configured by the block at
+// blockPath, or nil if the block is not configured.
+func newLocalCommandEphemeralHook(ctx context.Context, blockPath path.Path, block types.Object) (*localCommandEphemeralHook, diag.Diagnostics) {
+	if block.IsNull() {
+		return nil, nil
+	}
+
+	var config localCommandEphemeralHookModel
+	diags := block.As(ctx, &config, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return nil, diags
 	}
 
-	// We need to raise a diagnostic because the command wasn't successfully started and we have no exit code.
-	resp.Diagnostics.AddAttributeError(
-		path.Root("command"),
-		"Command Execution Failed",
-		"The ephemeral resource received an unexpected error while attempting to execute the command."+
-			"\n\n"+
-			fmt.Sprintf("Command: %s\n", cmd.String())+
-			fmt.Sprintf("State: %s", commandErr),
-	)
+	return &localCommandEphemeralHook{
+		Block:                         blockPath.String(),
+		Name:                          config.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(config.Arguments),
+		WorkingDirectory:              config.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(config.Environment),
+		SensitiveEnvironment:          localcommand.Environment(config.SensitiveEnvironment),
+		InheritEnvironment:            config.InheritEnvironment.ValueString(),
+		InheritedEnvironmentVariables: localcommand.Strings(config.InheritedEnvironmentVariables),
+		RunAsUser:                     config.RunAsUser.ValueString(),
+		RunAsGroup:                    config.RunAsGroup.ValueString(),
+		Limits:                        config.Limits.limits(),
+		Sandbox:                       config.Sandbox.sandbox(),
+		Timeout:                       config.Timeout.ValueDuration(),
+		TerminationGracePeriod:        config.TerminationGracePeriod.ValueDuration(),
+	}, diags
+}
+
+// Path returns the path of the block that configured the command.
+func (h *localCommandEphemeralHook) Path() path.Path {
+	return path.Root(h.Block)
+}
+
+// runAs returns the user and group the command runs as.
+func (h *localCommandEphemeralHook) runAs() localcommand.RunAs {
+	return localcommand.RunAs{
+		User:  h.RunAsUser,
+		Group: h.RunAsGroup,
+	}
+}
+
+func (h *localCommandEphemeralHook) command(stdin []byte) *localcommand.Command {
+	return &localcommand.Command{
+		Kind:                          localcommand.KindEphemeralResource,
+		Path:                          h.Path(),
+		Name:                          h.Name,
+		Arguments:                     h.Arguments,
+		WorkingDirectory:              h.WorkingDirectory,
+		Environment:                   h.Environment,
+		SensitiveEnvironment:          h.SensitiveEnvironment,
+		InheritEnvironment:            localcommand.InheritEnvironment(h.InheritEnvironment),
+		InheritedEnvironmentVariables: h.InheritedEnvironmentVariables,
+		RunAs:                         h.runAs(),
+		Limits:                        h.Limits,
+		Sandbox:                       h.Sandbox,
+		Stdin:                         stdin,
+		Timeout:                       h.Timeout,
+		TerminationGracePeriod:        h.TerminationGracePeriod,
+	}
 }
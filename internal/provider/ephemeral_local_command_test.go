@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
@@ -617,3 +618,249 @@ func TestLocalCommandEphemeral_not_found(t *testing.T) {
 		},
 	})
 }
+
+func TestLocalCommandEphemeral_denied_commands(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `provider "local" {
+					denied_commands = ["bash"]
+				}
+
+				ephemeral "local_command" "test" {
+					command   = "bash"
+					arguments = ["-c", "echo hello"]
+				}
+
+				provider "echo" {
+					data = {
+						stdout = ephemeral.local_command.test.stdout
+					}
+				}
+
+				resource "echo" "test" {}`,
+				ExpectError: regexp.MustCompile(`The command is denied by the denied_commands provider configuration`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_timeout(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command   = "sleep"
+					arguments = ["30"]
+					timeout   = "1s"
+				}
+
+				provider "echo" {
+					data = {
+						stdout = ephemeral.local_command.test.stdout
+					}
+				}
+
+				resource "echo" "test" {}`,
+				ExpectError: regexp.MustCompile(`Command Timed Out`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_environment(t *testing.T) {
+	t.Setenv("LOCAL_COMMAND_INHERITED", "inherited")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command             = "sh"
+					arguments           = ["-c", "echo -n \"$LOCAL_COMMAND_INHERITED|$LOCAL_COMMAND_SET\""]
+					inherit_environment = "none"
+					environment = {
+						LOCAL_COMMAND_SET = "set"
+					}
+				}
+
+				provider "echo" {
+					data = {
+						stdout = ephemeral.local_command.test.stdout
+					}
+				}
+
+				resource "echo" "test" {}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("stdout"), knownvalue.StringExact("|set")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_output_format(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command       = "printf"
+					arguments     = ["name = \"test\"\nport = 8080\n"]
+					output_format = "toml"
+				}
+
+				provider "echo" {
+					data = ephemeral.local_command.test.stdout_decoded
+				}
+
+				resource "echo" "test" {}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"name": knownvalue.StringExact("test"),
+						"port": knownvalue.Int64Exact(8080),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_base64(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command      = "cat"
+					stdin_base64 = "AP8K"
+				}
+
+				provider "echo" {
+					data = {
+						stdout_base64 = ephemeral.local_command.test.stdout_base64
+					}
+				}
+
+				resource "echo" "test" {}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("stdout_base64"), knownvalue.StringExact("AP8K")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_close_command(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "closed.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"echo": echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`ephemeral "local_command" "test" {
+					command   = "printf"
+					arguments = ["lease-1234"]
+
+					close_command {
+						command   = "bash"
+						arguments = ["-c", "cat > \"$1\"", "close", %q]
+					}
+				}
+
+				provider "echo" {
+					data = ephemeral.local_command.test.stdout
+				}
+
+				resource "echo" "test" {}`, testFile),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.StringExact("lease-1234")),
+				},
+				// The close command is passed the output of the command.
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "lease-1234")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_renew_command_without_interval(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command = "echo"
+
+					renew_command {
+						command = "true"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`Attribute "renew_interval" must be specified when "renew_command" is\s+specified`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandEphemeral_close_command_missing_command(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `ephemeral "local_command" "test" {
+					command = "echo"
+
+					close_command {
+						arguments = ["hello"]
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The "command" attribute must be configured in the "close_command" block`),
+			},
+		},
+	})
+}
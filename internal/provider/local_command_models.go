@@ -0,0 +1,131 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
+)
+
+// findCommand verifies that the command can be found, that the provider
+// configuration allows it to be executed and that the user it runs as can
+// execute it.
+func findCommand(providerData *localProviderData, attributePath path.Path, kind localcommand.Kind, command string, runAs localcommand.RunAs) diag.Diagnostics {
+	diags := localcommand.Lookup(attributePath, kind, command)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(providerData.checkCommand(attributePath, command)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	return localcommand.CheckRunAs(attributePath, kind, command, runAs)
+}
+
+// localCommandRetryModel is the retry block of the local_command data source
+// and action.
+type localCommandRetryModel struct {
+	MaxAttempts        types.Int64              `tfsdk:"max_attempts"`
+	InitialDelay       localtypes.DurationValue `tfsdk:"initial_delay"`
+	MaxDelay           localtypes.DurationValue `tfsdk:"max_delay"`
+	RetryOnExitCodes   types.Set                `tfsdk:"retry_on_exit_codes"`
+	RetryOnStderrRegex localtypes.RegexpValue   `tfsdk:"retry_on_stderr_regex"`
+}
+
+// retry returns the retry policy of the block, or nil if the block is not
+// configured.
+func (m *localCommandRetryModel) retry() *localcommand.Retry {
+	if m == nil {
+		return nil
+	}
+
+	return &localcommand.Retry{
+		MaxAttempts:  int(m.MaxAttempts.ValueInt64()),
+		InitialDelay: m.InitialDelay.ValueDuration(),
+		MaxDelay:     m.MaxDelay.ValueDuration(),
+		ExitCodes:    localcommand.ExitCodes(m.RetryOnExitCodes),
+		StderrRegex:  m.RetryOnStderrRegex.ValueRegexp(),
+	}
+}
+
+// localCommandLimitsModel is the limits block of the local_command entry
+// points.
+type localCommandLimitsModel struct {
+	MaxMemoryBytes types.Int64 `tfsdk:"max_memory_bytes"`
+	MaxCPUSeconds  types.Int64 `tfsdk:"max_cpu_seconds"`
+	MaxOpenFiles   types.Int64 `tfsdk:"max_open_files"`
+	MaxProcesses   types.Int64 `tfsdk:"max_processes"`
+	Nice           types.Int64 `tfsdk:"nice"`
+}
+
+// limits returns the limits of the block, or nil if the block is not
+// configured.
+func (m *localCommandLimitsModel) limits() *localcommand.Limits {
+	if m == nil {
+		return nil
+	}
+
+	limits := &localcommand.Limits{
+		MaxMemoryBytes: m.MaxMemoryBytes.ValueInt64(),
+		MaxCPUSeconds:  m.MaxCPUSeconds.ValueInt64(),
+		MaxOpenFiles:   m.MaxOpenFiles.ValueInt64(),
+		MaxProcesses:   m.MaxProcesses.ValueInt64(),
+	}
+
+	if !m.Nice.IsNull() {
+		nice := int(m.Nice.ValueInt64())
+		limits.Nice = &nice
+	}
+
+	return limits
+}
+
+// localCommandSandboxModel is the sandbox block of the local_command entry
+// points.
+type localCommandSandboxModel struct {
+	ReadOnlyPaths  types.List `tfsdk:"read_only_paths"`
+	ReadWritePaths types.List `tfsdk:"read_write_paths"`
+}
+
+// sandbox returns the sandbox of the block, or nil if the block is not
+// configured.
+func (m *localCommandSandboxModel) sandbox() *localcommand.Sandbox {
+	if m == nil {
+		return nil
+	}
+
+	return &localcommand.Sandbox{
+		ReadOnlyPaths:  localcommand.Strings(m.ReadOnlyPaths),
+		ReadWritePaths: localcommand.Strings(m.ReadWritePaths),
+	}
+}
+
+// getConfigBlock returns the model of the single nested block at blockPath,
+// or nil if the block is not configured or is unknown, which is the case when
+// it is generated by a dynamic block whose for_each is not yet known.
+func getConfigBlock[T any](ctx context.Context, config tfsdk.Config, blockPath path.Path) (*T, diag.Diagnostics) {
+	var block types.Object
+	diags := config.GetAttribute(ctx, blockPath, &block)
+	if diags.HasError() || block.IsNull() || block.IsUnknown() {
+		return nil, diags
+	}
+
+	var model T
+	diags.Append(block.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &model, diags
+}
@@ -0,0 +1,987 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	actionschema "github.com/hashicorp/terraform-plugin-framework/action/schema"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
+)
+
+// The schema shared by the local_command entry points is described once
+// here. As every kind of entry point has its own schema package, each shared
+// attribute or block has one constructor per kind.
+
+const (
+	localCommandArgumentsDescription = "Arguments to be passed to the given command. Any `null` arguments will be removed from the list."
+
+	localCommandWorkingDirectoryDescription = "The directory path where the command should be executed, either an absolute path or relative to the Terraform working directory. " +
+		"If not provided, defaults to the Terraform working directory."
+
+	localCommandTimeoutDescription = "The maximum amount of time the command is allowed to run, as a duration string such as `30s` or `5m`. " +
+		"If the command is still running when the timeout expires, its process group is sent `SIGTERM`, followed by `SIGKILL` " +
+		"if it has not exited after `termination_grace_period`. On Windows, the command is forcibly stopped immediately. If not provided, the command may run indefinitely."
+
+	localCommandTerminationGracePeriodDescription = "The amount of time the command's process group is given to exit after being sent `SIGTERM`, because of `timeout` or " +
+		"because Terraform was interrupted, before it is sent `SIGKILL`, as a duration string such as `10s`. Defaults to `10s`."
+
+	localCommandEnvironmentDescription = "Environment variables to set for the command. These are merged with the environment variables inherited from the Terraform process, " +
+		"with these values taking precedence."
+
+	localCommandSensitiveEnvironmentDescription = "Sensitive environment variables to set for the command, such as tokens for helper binaries. These are merged on top of the inherited environment " +
+		"and `environment`, with these values taking precedence. The values are redacted from logs, diagnostics and the echoed command line."
+
+	localCommandInheritEnvironmentDescription = "Which environment variables of the Terraform process are passed through to the command. Valid values are `all`, `none` and `allowlist`. " +
+		"With `none`, the command only receives the variables set in `environment`, which allows it to run hermetically; note that this includes `PATH`. " +
+		"With `allowlist`, only the variables named in `inherited_environment_variables` are passed through. Defaults to `all`."
+
+	localCommandInheritedEnvironmentVariablesDescription = "Names of the environment variables of the Terraform process that are passed through to the command. " +
+		"Can only be set, and must be set, when `inherit_environment` is `allowlist`."
+
+	localCommandScriptDescription = "A script to be run by `interpreter` instead of `command`, for example, a short inline shell script that would otherwise be maintained as a separate file. " +
+		"The script is written to an executable temporary file that is only accessible by the current user, passed to the interpreter followed by `arguments`, " +
+		"and removed once the command exits."
+
+	localCommandInterpreterDescription = "The executable, and any leading arguments, used to run `script`, for example, `[\"bash\", \"-eu\"]` or `[\"python3\"]`. " +
+		"The path of the script file is appended, followed by `arguments`, so the interpreter must accept the path of a script file rather than the script itself. " +
+		"If the last element is `-c`, the interpreter is treated as a shell and passed a command string running the script file instead, followed by the path " +
+		"of the file as `$0` and `arguments`. The script file is only read by the interpreter, never executed itself, so scripts run even when the temporary directory " +
+		"is mounted with `noexec`, and a `#!` line in the script is ignored; set `interpreter` to select another interpreter. " +
+		"The executable must be allowed by the `allowed_commands` and `denied_commands` provider configuration. Defaults to `[\"/bin/sh\", \"-c\"]`."
+
+	localCommandStdinBase64Description = "Data to be passed to the given command's standard input, encoded as a base64 string. Use this instead of `stdin` for binary data. " +
+		"Conflicts with `stdin`."
+
+	localCommandMaxOutputBytesDescription = "The maximum number of bytes captured from each of the command's standard output and standard error streams. " +
+		"What happens when a stream exceeds the limit is controlled by `output_truncation`. If not provided, output is not limited."
+
+	localCommandAllowNonZeroExitCodeDescription = "Indicates that the command returning a non-zero exit code should be treated as a successful execution. " +
+		"Further assertions can be made of the `exit_code` value with the [`check` block](https://developer.hashicorp.com/terraform/language/block/check). Defaults to false."
+
+	localCommandSuccessExitCodesDescription = "The exit codes that indicate that the command succeeded, for example, `[0, 1]` for commands such as `diff` or `grep` that use the exit code to report their result, " +
+		"or `[0, 2]` for `terraform plan -detailed-exitcode`. Any other exit code will be treated as an error. Defaults to `[0]`."
+
+	localCommandRetryMaxAttemptsDescription = "The maximum number of times the command is run, including the first attempt. Must be set when the block is configured."
+
+	localCommandRetryInitialDelayDescription = "The delay before the second attempt, as a duration string such as `500ms` or `5s`. The delay is doubled after every further attempt, " +
+		"up to `max_delay`. Defaults to `1s`."
+
+	localCommandRetryMaxDelayDescription = "The maximum delay between attempts, as a duration string such as `30s`. Defaults to `30s`."
+
+	localCommandRetryOnExitCodesDescription = "If set, only failed attempts that exited with one of these exit codes are retried."
+
+	localCommandRetryOnStderrRegexDescription = "If set, only failed attempts whose standard error matches this regular expression are retried. " +
+		"Uses the same syntax as the [`regex`](https://developer.hashicorp.com/terraform/language/functions/regex) function."
+
+	localCommandRunAsUserDescription = "The name or numeric ID of the user to run the command as, such as to run a helper command unprivileged when Terraform runs as root. " +
+		"The command also runs with the supplementary groups of the user. The environment variables passed to the command are not changed, so variables such as `HOME` " +
+		"may need to be set in `environment`. Only supported on Linux, where running as another user typically requires Terraform to run as root."
+
+	localCommandRunAsGroupDescription = "The name or numeric ID of the primary group to run the command as. Defaults to the primary group of `run_as_user`, " +
+		"and must be set if `run_as_user` is a numeric ID without a user account. Only supported on Linux."
+
+	localCommandLimitsDescription = "Limits on the resources that the command, and every process it starts, can use, such as to stop a misbehaving command from exhausting the memory " +
+		"or file descriptors of a shared machine. The limits are applied before the executable runs. If the command is killed because of the `max_cpu_seconds` or " +
+		"`max_memory_bytes` limit, the diagnostic names the limit, while reaching the other limits makes system calls fail, which the command reports itself. " +
+		"The limits can only be lowered, so a `max_*` value above the current hard limit of Terraform is an error. The limits are applied by running " +
+		"the provider executable, which then executes the command, so with `run_as_user` or `run_as_group` that user must also be able to execute " +
+		"the provider executable and search the directories leading to it, which is typically not the case within a private home directory. " +
+		"Only supported on Linux."
+
+	localCommandSandboxDescription = "Runs the command in a filesystem sandbox enforced by the [Landlock](https://docs.kernel.org/userspace-api/landlock.html) security module " +
+		"of the Linux kernel, such as to run untrusted scripts from a module with much less risk. The command, and every process it starts, can only access " +
+		"the configured paths, which must therefore also cover the executable, its shared libraries and any files it reads, such as `/usr`, `/lib` and `/etc`. " +
+		"The command also cannot gain privileges, such as with `sudo`. If the sandbox cannot be set up, such as when the kernel does not support Landlock, " +
+		"the command is not run and an error is returned. Only supported on Linux 5.13 or later."
+)
+
+// localCommandLimitAttribute describes an attribute of the limits block.
+type localCommandLimitAttribute struct {
+	description string
+	validator   validator.Int64
+}
+
+// localCommandLimitAttributes are the attributes of the limits block.
+var localCommandLimitAttributes = map[string]localCommandLimitAttribute{
+	"max_memory_bytes": {
+		description: "The maximum size of the virtual memory of each process, in bytes (`RLIMIT_AS`). Allocations beyond the limit fail, which typically makes " +
+			"the command exit with an out of memory error. Note that some runtimes reserve much more virtual memory than they use.",
+		validator: int64validator.AtLeast(1),
+	},
+	"max_cpu_seconds": {
+		description: "The maximum CPU time of each process, in seconds (`RLIMIT_CPU`). A process that exceeds the limit is sent `SIGXCPU`, " +
+			"followed by `SIGKILL` a second of CPU time later. Unlike `timeout`, time spent waiting does not count towards the limit.",
+		validator: int64validator.AtLeast(1),
+	},
+	"max_open_files": {
+		description: "The maximum number of file descriptors each process can open (`RLIMIT_NOFILE`), including its standard streams.",
+		validator:   int64validator.AtLeast(1),
+	},
+	"max_processes": {
+		description: "The maximum number of processes of the user the command runs as (`RLIMIT_NPROC`), which limits how many processes the command can start. " +
+			"Note that every process of the user counts towards the limit, not only those of the command, and that the limit is not enforced for `root`, " +
+			"so it is best combined with `run_as_user`.",
+		validator: int64validator.AtLeast(1),
+	},
+	"nice": {
+		description: "The niceness of the command, from `-20` for the highest scheduling priority to `19` for the lowest. " +
+			"Setting a niceness lower than that of Terraform typically requires Terraform to run as root.",
+		validator: int64validator.Between(-20, 19),
+	},
+}
+
+// localCommandSandboxAttributes are the descriptions of the attributes of the
+// sandbox block, which are all lists of paths.
+var localCommandSandboxAttributes = map[string]string{
+	"read_only_paths": "Files and directories, including everything beneath them, that the command can read and execute, such as `path.module`. " +
+		"Either absolute paths or relative to the Terraform working directory. Every path must exist.",
+	"read_write_paths": "Files and directories, including everything beneath them, that the command can read, execute, write, create and remove, " +
+		"such as a temporary directory. Either absolute paths or relative to the Terraform working directory. Every path must exist.",
+}
+
+// localCommandOutputTruncationDescription returns the description of the
+// output_truncation attribute of the given kind of entry point.
+func localCommandOutputTruncationDescription(kind localcommand.Kind) string {
+	return "What happens when the command writes more than `max_output_bytes` to one of its output streams. " +
+		fmt.Sprintf("With `error`, the command is stopped and the %s returns a diagnostic to Terraform. ", kind) +
+		"With `truncate_head`, the beginning of the output is discarded and the last `max_output_bytes` are kept. " +
+		"With `truncate_tail`, the end of the output is discarded and the first `max_output_bytes` are kept. Defaults to `error`."
+}
+
+// localCommandSuccessRegexDescription returns the description of the
+// success_stdout_regex or success_stderr_regex attribute matching the given
+// output stream.
+func localCommandSuccessRegexDescription(stream string) string {
+	return fmt.Sprintf("A regular expression that, if it matches the command's %s, indicates that the command succeeded regardless of its exit code. ", stream) +
+		"Uses the same syntax as the [`regex`](https://developer.hashicorp.com/terraform/language/functions/regex) function, and only matches the output captured within `max_output_bytes`."
+}
+
+// localCommandFailureRegexDescription returns the description of the
+// failure_stdout_regex or failure_stderr_regex attribute of the given kind of
+// entry point matching the given output stream.
+func localCommandFailureRegexDescription(kind localcommand.Kind, stream string) string {
+	return fmt.Sprintf("A regular expression that, if it matches the command's %s, indicates that the command failed regardless of its exit code, ", stream) +
+		fmt.Sprintf("in which case the %s returns a diagnostic to Terraform. Takes precedence over `success_exit_codes`, `success_stdout_regex` and `success_stderr_regex`.", kind)
+}
+
+// localCommandRetryDescription returns the description of the retry block of
+// the given kind of entry point.
+func localCommandRetryDescription(kind localcommand.Kind) string {
+	return "Runs the command again when it exits by itself and fails, such as when a helper command talks to a local daemon that is briefly unavailable. " +
+		fmt.Sprintf("Commands that cannot be started, time out or exceed `max_output_bytes` are not retried. If every attempt fails, the %s returns a diagnostic to Terraform ", kind) +
+		"summarizing each attempt."
+}
+
+// localCommandAttributes adds the shared attributes to the attributes of an
+// entry point and returns them.
+func localCommandAttributes[T any](attributes map[string]T, shared ...map[string]T) map[string]T {
+	for _, s := range shared {
+		maps.Copy(attributes, s)
+	}
+
+	return attributes
+}
+
+// actionRunAsAttribute returns the run_as_user or run_as_group attribute of
+// the local_command action with the given description.
+func actionRunAsAttribute(description string) actionschema.StringAttribute {
+	return actionschema.StringAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// dataSourceRunAsAttribute returns the run_as_user or run_as_group attribute
+// of the local_command data source with the given description.
+func dataSourceRunAsAttribute(description string) datasourceschema.StringAttribute {
+	return datasourceschema.StringAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// ephemeralRunAsAttribute returns the run_as_user or run_as_group attribute
+// of the local_command ephemeral resource with the given description.
+func ephemeralRunAsAttribute(description string) ephemeralschema.StringAttribute {
+	return ephemeralschema.StringAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// resourceRunAsAttribute returns the run_as_user or run_as_group attribute
+// of the local_command resources with the given description.
+func resourceRunAsAttribute(description string, planModifiers ...planmodifier.String) resourceschema.StringAttribute {
+	return resourceschema.StringAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+		PlanModifiers: planModifiers,
+	}
+}
+
+// actionLimitsBlock returns the limits block of the local_command action.
+func actionLimitsBlock() actionschema.SingleNestedBlock {
+	attributes := make(map[string]actionschema.Attribute, len(localCommandLimitAttributes))
+	for name, attribute := range localCommandLimitAttributes {
+		attributes[name] = actionschema.Int64Attribute{
+			MarkdownDescription: attribute.description,
+			Optional:            true,
+			Validators:          []validator.Int64{attribute.validator},
+		}
+	}
+
+	return actionschema.SingleNestedBlock{
+		MarkdownDescription: localCommandLimitsDescription,
+		Attributes:          attributes,
+	}
+}
+
+// dataSourceLimitsBlock returns the limits block of the local_command data
+// source.
+func dataSourceLimitsBlock() datasourceschema.SingleNestedBlock {
+	attributes := make(map[string]datasourceschema.Attribute, len(localCommandLimitAttributes))
+	for name, attribute := range localCommandLimitAttributes {
+		attributes[name] = datasourceschema.Int64Attribute{
+			MarkdownDescription: attribute.description,
+			Optional:            true,
+			Validators:          []validator.Int64{attribute.validator},
+		}
+	}
+
+	return datasourceschema.SingleNestedBlock{
+		MarkdownDescription: localCommandLimitsDescription,
+		Attributes:          attributes,
+	}
+}
+
+// ephemeralLimitsBlock returns the limits block of the local_command
+// ephemeral resource.
+func ephemeralLimitsBlock() ephemeralschema.SingleNestedBlock {
+	attributes := make(map[string]ephemeralschema.Attribute, len(localCommandLimitAttributes))
+	for name, attribute := range localCommandLimitAttributes {
+		attributes[name] = ephemeralschema.Int64Attribute{
+			MarkdownDescription: attribute.description,
+			Optional:            true,
+			Validators:          []validator.Int64{attribute.validator},
+		}
+	}
+
+	return ephemeralschema.SingleNestedBlock{
+		MarkdownDescription: localCommandLimitsDescription,
+		Attributes:          attributes,
+	}
+}
+
+// resourceLimitsBlock returns the limits block of the local_command
+// resources.
+func resourceLimitsBlock(planModifiers ...planmodifier.Object) resourceschema.SingleNestedBlock {
+	attributes := make(map[string]resourceschema.Attribute, len(localCommandLimitAttributes))
+	for name, attribute := range localCommandLimitAttributes {
+		attributes[name] = resourceschema.Int64Attribute{
+			MarkdownDescription: attribute.description,
+			Optional:            true,
+			Validators:          []validator.Int64{attribute.validator},
+		}
+	}
+
+	return resourceschema.SingleNestedBlock{
+		MarkdownDescription: localCommandLimitsDescription,
+		Attributes:          attributes,
+		PlanModifiers:       planModifiers,
+	}
+}
+
+// actionSandboxBlock returns the sandbox block of the local_command action.
+func actionSandboxBlock() actionschema.SingleNestedBlock {
+	attributes := make(map[string]actionschema.Attribute, len(localCommandSandboxAttributes))
+	for name, description := range localCommandSandboxAttributes {
+		attributes[name] = actionschema.ListAttribute{
+			MarkdownDescription: description,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		}
+	}
+
+	return actionschema.SingleNestedBlock{
+		MarkdownDescription: localCommandSandboxDescription,
+		Attributes:          attributes,
+	}
+}
+
+// dataSourceSandboxBlock returns the sandbox block of the local_command data
+// source.
+func dataSourceSandboxBlock() datasourceschema.SingleNestedBlock {
+	attributes := make(map[string]datasourceschema.Attribute, len(localCommandSandboxAttributes))
+	for name, description := range localCommandSandboxAttributes {
+		attributes[name] = datasourceschema.ListAttribute{
+			MarkdownDescription: description,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		}
+	}
+
+	return datasourceschema.SingleNestedBlock{
+		MarkdownDescription: localCommandSandboxDescription,
+		Attributes:          attributes,
+	}
+}
+
+// ephemeralSandboxBlock returns the sandbox block of the local_command
+// ephemeral resource.
+func ephemeralSandboxBlock() ephemeralschema.SingleNestedBlock {
+	attributes := make(map[string]ephemeralschema.Attribute, len(localCommandSandboxAttributes))
+	for name, description := range localCommandSandboxAttributes {
+		attributes[name] = ephemeralschema.ListAttribute{
+			MarkdownDescription: description,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		}
+	}
+
+	return ephemeralschema.SingleNestedBlock{
+		MarkdownDescription: localCommandSandboxDescription,
+		Attributes:          attributes,
+	}
+}
+
+// resourceSandboxBlock returns the sandbox block of the local_command
+// resources.
+func resourceSandboxBlock(planModifiers ...planmodifier.Object) resourceschema.SingleNestedBlock {
+	attributes := make(map[string]resourceschema.Attribute, len(localCommandSandboxAttributes))
+	for name, description := range localCommandSandboxAttributes {
+		attributes[name] = resourceschema.ListAttribute{
+			MarkdownDescription: description,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		}
+	}
+
+	return resourceschema.SingleNestedBlock{
+		MarkdownDescription: localCommandSandboxDescription,
+		Attributes:          attributes,
+		PlanModifiers:       planModifiers,
+	}
+}
+
+// actionCommandAttributes returns the attributes of the local_command action
+// that configure how the command is run.
+func actionCommandAttributes() map[string]actionschema.Attribute {
+	return map[string]actionschema.Attribute{
+		"arguments": actionschema.ListAttribute{
+			MarkdownDescription: localCommandArgumentsDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"working_directory": actionschema.StringAttribute{
+			Description: localCommandWorkingDirectoryDescription,
+			Optional:    true,
+		},
+		"timeout": actionschema.StringAttribute{
+			MarkdownDescription: localCommandTimeoutDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"termination_grace_period": actionschema.StringAttribute{
+			MarkdownDescription: localCommandTerminationGracePeriodDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"environment": actionschema.MapAttribute{
+			Description: localCommandEnvironmentDescription,
+			ElementType: types.StringType,
+			Optional:    true,
+		},
+		"sensitive_environment": actionschema.MapAttribute{
+			MarkdownDescription: localCommandSensitiveEnvironmentDescription + " The values are also redacted from the displayed `stdout`. " +
+				"Action attributes cannot be marked as sensitive, so this attribute is write-only and accepts ephemeral values; pass values from sensitive variables or " +
+				"ephemeral resources so that Terraform also redacts them from its own output.",
+			ElementType: types.StringType,
+			Optional:    true,
+			WriteOnly:   true,
+		},
+		"inherit_environment": actionschema.StringAttribute{
+			MarkdownDescription: localCommandInheritEnvironmentDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.InheritEnvironmentModes()...),
+			},
+		},
+		"inherited_environment_variables": actionschema.ListAttribute{
+			MarkdownDescription: localCommandInheritedEnvironmentVariablesDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		},
+		"run_as_user":  actionRunAsAttribute(localCommandRunAsUserDescription),
+		"run_as_group": actionRunAsAttribute(localCommandRunAsGroupDescription),
+	}
+}
+
+// dataSourceCommandAttributes returns the attributes of the local_command data
+// source that configure how the command is run.
+func dataSourceCommandAttributes() map[string]datasourceschema.Attribute {
+	return map[string]datasourceschema.Attribute{
+		"arguments": datasourceschema.ListAttribute{
+			MarkdownDescription: localCommandArgumentsDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"working_directory": datasourceschema.StringAttribute{
+			Description: localCommandWorkingDirectoryDescription,
+			Optional:    true,
+		},
+		"timeout": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandTimeoutDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"termination_grace_period": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandTerminationGracePeriodDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"environment": datasourceschema.MapAttribute{
+			Description: localCommandEnvironmentDescription,
+			ElementType: types.StringType,
+			Optional:    true,
+		},
+		"sensitive_environment": datasourceschema.MapAttribute{
+			MarkdownDescription: localCommandSensitiveEnvironmentDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Sensitive:           true,
+		},
+		"inherit_environment": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandInheritEnvironmentDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.InheritEnvironmentModes()...),
+			},
+		},
+		"inherited_environment_variables": datasourceschema.ListAttribute{
+			MarkdownDescription: localCommandInheritedEnvironmentVariablesDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		},
+		"run_as_user":  dataSourceRunAsAttribute(localCommandRunAsUserDescription),
+		"run_as_group": dataSourceRunAsAttribute(localCommandRunAsGroupDescription),
+	}
+}
+
+// ephemeralCommandAttributes returns the attributes of the local_command
+// ephemeral resource that configure how its commands are run.
+func ephemeralCommandAttributes() map[string]ephemeralschema.Attribute {
+	return map[string]ephemeralschema.Attribute{
+		"arguments": ephemeralschema.ListAttribute{
+			MarkdownDescription: localCommandArgumentsDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"working_directory": ephemeralschema.StringAttribute{
+			Description: localCommandWorkingDirectoryDescription,
+			Optional:    true,
+		},
+		"timeout": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandTimeoutDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"termination_grace_period": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandTerminationGracePeriodDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"environment": ephemeralschema.MapAttribute{
+			Description: localCommandEnvironmentDescription,
+			ElementType: types.StringType,
+			Optional:    true,
+		},
+		"sensitive_environment": ephemeralschema.MapAttribute{
+			MarkdownDescription: localCommandSensitiveEnvironmentDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Sensitive:           true,
+		},
+		"inherit_environment": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandInheritEnvironmentDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.InheritEnvironmentModes()...),
+			},
+		},
+		"inherited_environment_variables": ephemeralschema.ListAttribute{
+			MarkdownDescription: localCommandInheritedEnvironmentVariablesDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		},
+		"run_as_user":  ephemeralRunAsAttribute(localCommandRunAsUserDescription),
+		"run_as_group": ephemeralRunAsAttribute(localCommandRunAsGroupDescription),
+	}
+}
+
+// resourceCommandAttributes returns the attributes that configure how a
+// command of the local_command resources is run. If requiresReplace is set,
+// changing any of them except the timeouts replaces the resource.
+func resourceCommandAttributes(requiresReplace bool) map[string]resourceschema.Attribute {
+	var (
+		listPlanModifiers   []planmodifier.List
+		mapPlanModifiers    []planmodifier.Map
+		stringPlanModifiers []planmodifier.String
+	)
+	if requiresReplace {
+		listPlanModifiers = []planmodifier.List{listplanmodifier.RequiresReplace()}
+		mapPlanModifiers = []planmodifier.Map{mapplanmodifier.RequiresReplace()}
+		stringPlanModifiers = []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	}
+
+	return map[string]resourceschema.Attribute{
+		"arguments": resourceschema.ListAttribute{
+			MarkdownDescription: localCommandArgumentsDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			PlanModifiers:       listPlanModifiers,
+		},
+		"working_directory": resourceschema.StringAttribute{
+			Description:   localCommandWorkingDirectoryDescription,
+			Optional:      true,
+			PlanModifiers: stringPlanModifiers,
+		},
+		"timeout": resourceschema.StringAttribute{
+			MarkdownDescription: localCommandTimeoutDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"termination_grace_period": resourceschema.StringAttribute{
+			MarkdownDescription: localCommandTerminationGracePeriodDescription,
+			CustomType:          localtypes.NewDurationType(),
+			Optional:            true,
+		},
+		"environment": resourceschema.MapAttribute{
+			Description:   localCommandEnvironmentDescription,
+			ElementType:   types.StringType,
+			Optional:      true,
+			PlanModifiers: mapPlanModifiers,
+		},
+		"sensitive_environment": resourceschema.MapAttribute{
+			MarkdownDescription: localCommandSensitiveEnvironmentDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Sensitive:           true,
+			PlanModifiers:       mapPlanModifiers,
+		},
+		"inherit_environment": resourceschema.StringAttribute{
+			MarkdownDescription: localCommandInheritEnvironmentDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.InheritEnvironmentModes()...),
+			},
+			PlanModifiers: stringPlanModifiers,
+		},
+		"inherited_environment_variables": resourceschema.ListAttribute{
+			MarkdownDescription: localCommandInheritedEnvironmentVariablesDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+			PlanModifiers: listPlanModifiers,
+		},
+		"run_as_user":  resourceRunAsAttribute(localCommandRunAsUserDescription, stringPlanModifiers...),
+		"run_as_group": resourceRunAsAttribute(localCommandRunAsGroupDescription, stringPlanModifiers...),
+	}
+}
+
+// actionScriptAttributes returns the attributes of the local_command action
+// that run a script instead of an executable and pass binary data to its
+// standard input.
+func actionScriptAttributes() map[string]actionschema.Attribute {
+	return map[string]actionschema.Attribute{
+		"script": actionschema.StringAttribute{
+			MarkdownDescription: localCommandScriptDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"interpreter": actionschema.ListAttribute{
+			MarkdownDescription: localCommandInterpreterDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				listvalidator.AlsoRequires(path.MatchRoot("script")),
+			},
+		},
+		"stdin_base64": actionschema.StringAttribute{
+			MarkdownDescription: localCommandStdinBase64Description,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRoot("stdin")),
+			},
+		},
+	}
+}
+
+// actionResultAttributes returns the attributes of the local_command action
+// that limit the output of the command and decide whether it succeeded.
+func actionResultAttributes() map[string]actionschema.Attribute {
+	return map[string]actionschema.Attribute{
+		"max_output_bytes": actionschema.Int64Attribute{
+			MarkdownDescription: localCommandMaxOutputBytesDescription,
+			Optional:            true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
+		"output_truncation": actionschema.StringAttribute{
+			MarkdownDescription: localCommandOutputTruncationDescription(localcommand.KindAction),
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.OutputTruncations()...),
+				stringvalidator.AlsoRequires(path.MatchRoot("max_output_bytes")),
+			},
+		},
+		"success_exit_codes": actionschema.SetAttribute{
+			MarkdownDescription: localCommandSuccessExitCodesDescription,
+			ElementType:         types.Int64Type,
+			Optional:            true,
+			Validators: []validator.Set{
+				setvalidator.SizeAtLeast(1),
+			},
+		},
+		"success_stdout_regex": actionschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"success_stderr_regex": actionschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stdout_regex": actionschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindAction, "standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stderr_regex": actionschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindAction, "standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+	}
+}
+
+// dataSourceScriptAttributes returns the attributes of the local_command data
+// source that run a script instead of an executable and pass binary data to
+// its standard input.
+func dataSourceScriptAttributes() map[string]datasourceschema.Attribute {
+	return map[string]datasourceschema.Attribute{
+		"script": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandScriptDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"interpreter": datasourceschema.ListAttribute{
+			MarkdownDescription: localCommandInterpreterDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				listvalidator.AlsoRequires(path.MatchRoot("script")),
+			},
+		},
+		"stdin_base64": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandStdinBase64Description,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRoot("stdin")),
+			},
+		},
+	}
+}
+
+// dataSourceResultAttributes returns the attributes of the local_command data
+// source that limit the output of the command and decide whether it
+// succeeded.
+func dataSourceResultAttributes() map[string]datasourceschema.Attribute {
+	return map[string]datasourceschema.Attribute{
+		"max_output_bytes": datasourceschema.Int64Attribute{
+			MarkdownDescription: localCommandMaxOutputBytesDescription,
+			Optional:            true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
+		"output_truncation": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandOutputTruncationDescription(localcommand.KindDataSource),
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.OutputTruncations()...),
+				stringvalidator.AlsoRequires(path.MatchRoot("max_output_bytes")),
+			},
+		},
+		"allow_non_zero_exit_code": datasourceschema.BoolAttribute{
+			MarkdownDescription: localCommandAllowNonZeroExitCodeDescription,
+			Optional:            true,
+		},
+		"success_exit_codes": datasourceschema.SetAttribute{
+			MarkdownDescription: localCommandSuccessExitCodesDescription + " Conflicts with `allow_non_zero_exit_code`.",
+			ElementType:         types.Int64Type,
+			Optional:            true,
+			Validators: []validator.Set{
+				setvalidator.SizeAtLeast(1),
+				setvalidator.ConflictsWith(path.MatchRoot("allow_non_zero_exit_code")),
+			},
+		},
+		"success_stdout_regex": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"success_stderr_regex": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stdout_regex": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindDataSource, "standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stderr_regex": datasourceschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindDataSource, "standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+	}
+}
+
+// ephemeralScriptAttributes returns the attributes of the local_command
+// ephemeral resource that run a script instead of an executable and pass
+// binary data to its standard input.
+func ephemeralScriptAttributes() map[string]ephemeralschema.Attribute {
+	return map[string]ephemeralschema.Attribute{
+		"script": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandScriptDescription,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"interpreter": ephemeralschema.ListAttribute{
+			MarkdownDescription: localCommandInterpreterDescription,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				listvalidator.AlsoRequires(path.MatchRoot("script")),
+			},
+		},
+		"stdin_base64": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandStdinBase64Description,
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRoot("stdin")),
+			},
+		},
+	}
+}
+
+// ephemeralResultAttributes returns the attributes of the local_command
+// ephemeral resource that limit the output of the command and decide whether
+// it succeeded.
+func ephemeralResultAttributes() map[string]ephemeralschema.Attribute {
+	return map[string]ephemeralschema.Attribute{
+		"max_output_bytes": ephemeralschema.Int64Attribute{
+			MarkdownDescription: localCommandMaxOutputBytesDescription,
+			Optional:            true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
+		"output_truncation": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandOutputTruncationDescription(localcommand.KindEphemeralResource),
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(localcommand.OutputTruncations()...),
+				stringvalidator.AlsoRequires(path.MatchRoot("max_output_bytes")),
+			},
+		},
+		"allow_non_zero_exit_code": ephemeralschema.BoolAttribute{
+			MarkdownDescription: localCommandAllowNonZeroExitCodeDescription,
+			Optional:            true,
+		},
+		"success_exit_codes": ephemeralschema.SetAttribute{
+			MarkdownDescription: localCommandSuccessExitCodesDescription + " Conflicts with `allow_non_zero_exit_code`.",
+			ElementType:         types.Int64Type,
+			Optional:            true,
+			Validators: []validator.Set{
+				setvalidator.SizeAtLeast(1),
+				setvalidator.ConflictsWith(path.MatchRoot("allow_non_zero_exit_code")),
+			},
+		},
+		"success_stdout_regex": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"success_stderr_regex": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandSuccessRegexDescription("standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stdout_regex": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindEphemeralResource, "standard output"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+		"failure_stderr_regex": ephemeralschema.StringAttribute{
+			MarkdownDescription: localCommandFailureRegexDescription(localcommand.KindEphemeralResource, "standard error"),
+			CustomType:          localtypes.NewRegexpType(),
+			Optional:            true,
+		},
+	}
+}
+
+// actionRetryBlock returns the retry block of the local_command action.
+func actionRetryBlock() actionschema.SingleNestedBlock {
+	return actionschema.SingleNestedBlock{
+		MarkdownDescription: localCommandRetryDescription(localcommand.KindAction),
+		Validators: []validator.Object{
+			objectvalidator.AlsoRequires(path.MatchRelative().AtName("max_attempts")),
+		},
+		Attributes: map[string]actionschema.Attribute{
+			"max_attempts": actionschema.Int64Attribute{
+				Description: localCommandRetryMaxAttemptsDescription,
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"initial_delay": actionschema.StringAttribute{
+				MarkdownDescription: localCommandRetryInitialDelayDescription,
+				CustomType:          localtypes.NewDurationType(),
+				Optional:            true,
+			},
+			"max_delay": actionschema.StringAttribute{
+				MarkdownDescription: localCommandRetryMaxDelayDescription,
+				CustomType:          localtypes.NewDurationType(),
+				Optional:            true,
+			},
+			"retry_on_exit_codes": actionschema.SetAttribute{
+				Description: localCommandRetryOnExitCodesDescription,
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"retry_on_stderr_regex": actionschema.StringAttribute{
+				MarkdownDescription: localCommandRetryOnStderrRegexDescription,
+				CustomType:          localtypes.NewRegexpType(),
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// dataSourceRetryBlock returns the retry block of the local_command data
+// source.
+func dataSourceRetryBlock() datasourceschema.SingleNestedBlock {
+	return datasourceschema.SingleNestedBlock{
+		MarkdownDescription: localCommandRetryDescription(localcommand.KindDataSource),
+		Validators: []validator.Object{
+			objectvalidator.AlsoRequires(path.MatchRelative().AtName("max_attempts")),
+		},
+		Attributes: map[string]datasourceschema.Attribute{
+			"max_attempts": datasourceschema.Int64Attribute{
+				Description: localCommandRetryMaxAttemptsDescription,
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"initial_delay": datasourceschema.StringAttribute{
+				MarkdownDescription: localCommandRetryInitialDelayDescription,
+				CustomType:          localtypes.NewDurationType(),
+				Optional:            true,
+			},
+			"max_delay": datasourceschema.StringAttribute{
+				MarkdownDescription: localCommandRetryMaxDelayDescription,
+				CustomType:          localtypes.NewDurationType(),
+				Optional:            true,
+			},
+			"retry_on_exit_codes": datasourceschema.SetAttribute{
+				Description: localCommandRetryOnExitCodesDescription,
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"retry_on_stderr_regex": datasourceschema.StringAttribute{
+				MarkdownDescription: localCommandRetryOnStderrRegexDescription,
+				CustomType:          localtypes.NewRegexpType(),
+				Optional:            true,
+			},
+		},
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localuser"
+)
+
+type fileChecksums struct {
+	md5Hex       string
+	sha1Hex      string
+	sha256Hex    string
+	sha256Base64 string
+	sha512Hex    string
+	sha512Base64 string
+}
+
+func genFileChecksums(data []byte) fileChecksums {
+	checksummer := newFileChecksummer()
+	_, _ = checksummer.Write(data)
+
+	return checksummer.checksums()
+}
+
+// fileChecksummer computes the fileChecksums of the data written to it, so
+// that content streamed to a file does not need to be held in memory.
+type fileChecksummer struct {
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+	sha512 hash.Hash
+}
+
+func newFileChecksummer() *fileChecksummer {
+	return &fileChecksummer{
+		md5:    md5.New(),
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+		sha512: sha512.New(),
+	}
+}
+
+func (c *fileChecksummer) Write(p []byte) (int, error) {
+	for _, h := range []hash.Hash{c.md5, c.sha1, c.sha256, c.sha512} {
+		// Writing to a hash never returns an error.
+		_, _ = h.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// Reset discards everything written so far.
+func (c *fileChecksummer) Reset() {
+	for _, h := range []hash.Hash{c.md5, c.sha1, c.sha256, c.sha512} {
+		h.Reset()
+	}
+}
+
+func (c *fileChecksummer) checksums() fileChecksums {
+	sha256Sum := c.sha256.Sum(nil)
+	sha512Sum := c.sha512.Sum(nil)
+
+	return fileChecksums{
+		md5Hex:       hex.EncodeToString(c.md5.Sum(nil)),
+		sha1Hex:      hex.EncodeToString(c.sha1.Sum(nil)),
+		sha256Hex:    hex.EncodeToString(sha256Sum),
+		sha256Base64: base64.StdEncoding.EncodeToString(sha256Sum),
+		sha512Hex:    hex.EncodeToString(sha512Sum),
+		sha512Base64: base64.StdEncoding.EncodeToString(sha512Sum),
+	}
+}
+
+// localFileStatus is the content checksums, size and permissions of a file
+// as read from disk.
+type localFileStatus struct {
+	checksums fileChecksums
+	size      int64
+	perm      os.FileMode
+
+	// owner is nil on platforms without file ownership.
+	owner *fileOwner
+}
+
+// readLocalFileStatus reads the file to compute its localFileStatus. If the
+// file does not exist, the error satisfies os.IsNotExist.
+func readLocalFileStatus(name string) (*localFileStatus, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	checksummer := newFileChecksummer()
+
+	size, err := io.Copy(checksummer, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localFileStatus{
+		checksums: checksummer.checksums(),
+		size:      size,
+		perm:      info.Mode().Perm(),
+		owner:     fileOwnerOf(info),
+	}, nil
+}
+
+const (
+	// driftPolicyRecreate rewrites a file which was changed outside of
+	// Terraform with its configured content.
+	driftPolicyRecreate = "recreate"
+
+	// driftPolicyIgnore keeps a file which was changed outside of Terraform.
+	driftPolicyIgnore = "ignore"
+
+	// driftPolicyError fails to refresh a file which was changed outside of
+	// Terraform.
+	driftPolicyError = "error"
+)
+
+const (
+	// writeModeAtomic writes a file to a temporary file in the same
+	// directory, which is then renamed over the destination.
+	writeModeAtomic = "atomic"
+
+	// writeModeDirect writes a file to the destination directly.
+	writeModeDirect = "direct"
+)
+
+// writeLocalFile writes content to the destination file, creating it with
+// perm (before umask) if it does not exist, and changes its owner if owner is
+// not nil. With writeModeAtomic, or an empty mode, readers of the destination
+// either see the previous content or the complete new content, with its
+// owner, even if the provider crashes while writing it. If syncDirectory is
+// set, the parent directory is also synced to disk, so that the new directory
+// entry survives a crash of the machine.
+func writeLocalFile(destination string, content []byte, perm os.FileMode, owner *fileOwner, mode string, syncDirectory bool) error {
+	if mode == writeModeDirect {
+		if err := os.WriteFile(destination, content, perm); err != nil {
+			return err
+		}
+
+		if err := owner.chown(destination); err != nil {
+			return err
+		}
+	} else if err := writeLocalFileAtomic(destination, content, perm, owner); err != nil {
+		return err
+	}
+
+	if syncDirectory {
+		return syncDir(filepath.Dir(destination))
+	}
+
+	return nil
+}
+
+// writeLocalFileAtomic writes content to a temporary file next to the
+// destination, syncs it to disk and renames it over the destination.
+func writeLocalFileAtomic(destination string, content []byte, perm os.FileMode, owner *fileOwner) error {
+	temp, err := createTempFile(destination, perm)
+	if err != nil {
+		return err
+	}
+
+	// The temporary file is removed unless it has been renamed.
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err := temp.Write(content); err != nil {
+		return err
+	}
+
+	if err := owner.chown(temp.Name()); err != nil {
+		return err
+	}
+
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), destination); err != nil {
+		return err
+	}
+
+	renamed = true
+
+	return nil
+}
+
+// createTempFile creates a new, hidden file with a random name in the
+// directory of the destination file. Unlike os.CreateTemp, the file is
+// created with perm, so that the umask applies as it does when the
+// destination is written directly.
+func createTempFile(destination string, perm os.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(destination)
+
+	for {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%s.tmp", base, hex.EncodeToString(suffix)))
+
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+
+		return file, err
+	}
+}
+
+// chmodLocalFile changes the permissions of the destination file to those
+// writeLocalFile would create it with, that is perm without the permissions
+// removed by the umask, and returns the permissions the file then has.
+func chmodLocalFile(destination string, perm os.FileMode) (os.FileMode, error) {
+	if err := os.Chmod(destination, perm&^umask); err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Mode().Perm(), nil
+}
+
+// fileOwner is the user and group IDs to own a file, where -1 leaves the ID
+// unchanged.
+type fileOwner struct {
+	uid int
+	gid int
+}
+
+// resolveFileOwner resolves the names or numeric IDs of the user and group to
+// own a file. It returns nil if neither is configured.
+func resolveFileOwner(user, group types.String) (*fileOwner, error) {
+	if user.IsNull() && group.IsNull() {
+		return nil, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("file ownership is not supported on Windows")
+	}
+
+	uid, gid, err := localuser.LookupOwner(user.ValueString(), group.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileOwner{uid: uid, gid: gid}, nil
+}
+
+// chown changes the owner of the named file. It does nothing if the owner is
+// nil.
+func (o *fileOwner) chown(name string) error {
+	if o == nil {
+		return nil
+	}
+
+	return os.Chown(name, o.uid, o.gid)
+}
+
+// differs reports whether the actual owner of a file differs from the owner,
+// ignoring IDs which are left unchanged.
+func (o *fileOwner) differs(actual *fileOwner) bool {
+	if o == nil || actual == nil {
+		return false
+	}
+
+	return (o.uid != -1 && o.uid != actual.uid) || (o.gid != -1 && o.gid != actual.gid)
+}
+
+// mkdirAll creates the directory and any missing parents with perm (before
+// umask), like os.MkdirAll, and changes the owner of the directories it
+// created if owner is not nil.
+func mkdirAll(dir string, perm os.FileMode, owner *fileOwner) error {
+	var missing []string
+	for name := dir; ; name = filepath.Dir(name) {
+		if _, err := os.Stat(name); err == nil {
+			break
+		}
+
+		missing = append(missing, name)
+
+		if name == filepath.Dir(name) {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := owner.chown(missing[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncDir syncs the directory to disk. Directories cannot be synced on
+// Windows, where NTFS journals the changes to them instead, so this does
+// nothing there.
+func syncDir(name string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
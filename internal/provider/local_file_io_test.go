@@ -0,0 +1,249 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteLocalFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		mode                string
+		expectedLinkContent string
+		expectedSymlink     bool
+	}{
+		// The symbolic link is replaced by the file.
+		"atomic": {mode: writeModeAtomic, expectedLinkContent: "original", expectedSymlink: false},
+		"empty":  {mode: "", expectedLinkContent: "original", expectedSymlink: false},
+		// The content is written through the symbolic link.
+		"direct": {mode: writeModeDirect, expectedLinkContent: "content", expectedSymlink: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			target := filepath.Join(dir, "target")
+			destination := filepath.Join(dir, "destination")
+
+			if err := os.WriteFile(target, []byte("original"), 0600); err != nil {
+				t.Fatalf("unable to write file: %s", err)
+			}
+
+			if err := os.Symlink(target, destination); err != nil {
+				t.Skipf("unable to create symbolic link: %s", err)
+			}
+
+			if err := writeLocalFile(destination, []byte("content"), 0600, nil, testCase.mode, true); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(destination)
+			if err != nil {
+				t.Fatalf("unable to read file: %s", err)
+			}
+
+			if string(content) != "content" {
+				t.Errorf("expected content %q, got %q", "content", content)
+			}
+
+			linkContent, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatalf("unable to read file: %s", err)
+			}
+
+			if string(linkContent) != testCase.expectedLinkContent {
+				t.Errorf("expected link target content %q, got %q", testCase.expectedLinkContent, linkContent)
+			}
+
+			info, err := os.Lstat(destination)
+			if err != nil {
+				t.Fatalf("unable to stat file: %s", err)
+			}
+
+			if got := info.Mode()&os.ModeSymlink != 0; got != testCase.expectedSymlink {
+				t.Errorf("expected symbolic link %t, got %t", testCase.expectedSymlink, got)
+			}
+
+			// No temporary files are left behind.
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("unable to read directory: %s", err)
+			}
+
+			if len(entries) != 2 {
+				t.Errorf("expected 2 directory entries, got %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestWriteLocalFileAtomicPermissions(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on Windows")
+	}
+
+	destination := filepath.Join(t.TempDir(), "destination")
+
+	if err := writeLocalFile(destination, []byte("content"), 0640, nil, writeModeAtomic, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		t.Fatalf("unable to stat file: %s", err)
+	}
+
+	// The umask may remove further permissions.
+	if perm := info.Mode().Perm(); perm&^0640 != 0 {
+		t.Errorf("expected permissions within 0640, got %#o", perm)
+	}
+}
+
+func TestReadLocalFileStatus(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(name, []byte("This is some content"), 0600); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	status, err := readLocalFileStatus(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := genFileChecksums([]byte("This is some content")); status.checksums != expected {
+		t.Errorf("expected checksums %+v, got %+v", expected, status.checksums)
+	}
+
+	if status.size != 20 {
+		t.Errorf("expected size 20, got %d", status.size)
+	}
+
+	if runtime.GOOS != "windows" && status.perm != 0600 {
+		t.Errorf("expected permissions 0600, got %#o", status.perm)
+	}
+
+	if _, err := readLocalFileStatus(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+}
+
+func TestChmodLocalFile(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "destination")
+	if err := os.WriteFile(destination, []byte("content"), 0600); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	perm, err := chmodLocalFile(destination, 0640)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		t.Fatalf("unable to stat file: %s", err)
+	}
+
+	if info.Mode().Perm() != perm {
+		t.Errorf("expected permissions %#o, got %#o", perm, info.Mode().Perm())
+	}
+
+	// The umask removes the same permissions as when the file is written.
+	written := filepath.Join(t.TempDir(), "written")
+	if err := writeLocalFile(written, []byte("content"), 0640, nil, writeModeDirect, false); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	writtenInfo, err := os.Stat(written)
+	if err != nil {
+		t.Fatalf("unable to stat file: %s", err)
+	}
+
+	if writtenInfo.Mode().Perm() != perm {
+		t.Errorf("expected permissions %#o of written file, got %#o", writtenInfo.Mode().Perm(), perm)
+	}
+
+	// No temporary files are created in the directory.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unable to read directory: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 directory entry, got %d", len(entries))
+	}
+}
+
+func TestMkdirAll(t *testing.T) {
+	t.Parallel()
+
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of a directory requires root")
+	}
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "a", "b")
+
+	if err := mkdirAll(dir, 0700, &fileOwner{uid: 4321, gid: -1}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Only the directories which were created are owned by the owner.
+	for name, expectedUID := range map[string]int{parent: 0, filepath.Dir(dir): 4321, dir: 4321} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("unable to stat directory: %s", err)
+		}
+
+		if owner := fileOwnerOf(info); owner.uid != expectedUID || owner.gid != 0 {
+			t.Errorf("expected %s to be owned by %d:0, got %d:%d", name, expectedUID, owner.uid, owner.gid)
+		}
+	}
+}
+
+func TestWriteLocalFileOwner(t *testing.T) {
+	t.Parallel()
+
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of a file requires root")
+	}
+
+	for _, mode := range []string{writeModeAtomic, writeModeDirect} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			destination := filepath.Join(t.TempDir(), "destination")
+
+			if err := writeLocalFile(destination, []byte("content"), 0600, &fileOwner{uid: 4321, gid: 4321}, mode, false); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			info, err := os.Stat(destination)
+			if err != nil {
+				t.Fatalf("unable to stat file: %s", err)
+			}
+
+			if owner := fileOwnerOf(info); owner.uid != 4321 || owner.gid != 4321 {
+				t.Errorf("expected file to be owned by 4321:4321, got %d:%d", owner.uid, owner.gid)
+			}
+		})
+	}
+}
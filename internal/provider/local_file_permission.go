@@ -0,0 +1,74 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
+)
+
+// formatFilePermission formats permissions in the numeric notation of the
+// file_permission and directory_permission attributes.
+func formatFilePermission(perm os.FileMode) string {
+	return fmt.Sprintf("%04o", perm.Perm())
+}
+
+// parseFilePermission returns the mode of permissions in the numeric notation
+// of the file_permission and directory_permission attributes, or of defaultPerm
+// if they are null, as they are for an imported file until configured.
+func parseFilePermission(perm localtypes.FilePermissionValue, defaultPerm string) os.FileMode {
+	value := defaultPerm
+	if !perm.IsNull() {
+		value = perm.ValueString()
+	}
+
+	mode, _ := strconv.ParseInt(value, 8, 64)
+
+	return os.FileMode(mode)
+}
+
+var _ planmodifier.String = filePermissionDefaultModifier{}
+
+// filePermissionDefault returns a plan modifier which plans perm for the
+// file_permission or directory_permission attribute if it is not configured.
+//
+// Unlike a schema default, it leaves the attribute null if it is null in the
+// state, which it only is for an imported file: the permissions the file was
+// created with before the umask cannot be read from it, so that planning the
+// default would change the permissions of a file right after importing it.
+func filePermissionDefault(perm string) planmodifier.String {
+	return filePermissionDefaultModifier{perm: perm}
+}
+
+type filePermissionDefaultModifier struct {
+	perm string
+}
+
+func (m filePermissionDefaultModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("Defaults to %q, unless the resource was imported.", m.perm)
+}
+
+func (m filePermissionDefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m filePermissionDefaultModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() && req.StateValue.IsNull() {
+		resp.PlanValue = req.StateValue
+		return
+	}
+
+	resp.PlanValue = types.StringValue(m.perm)
+}
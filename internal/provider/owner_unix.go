@@ -0,0 +1,21 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package provider
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwnerOf returns the owner of the file.
+func fileOwnerOf(info os.FileInfo) *fileOwner {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return &fileOwner{uid: int(stat.Uid), gid: int(stat.Gid)}
+}
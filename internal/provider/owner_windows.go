@@ -0,0 +1,16 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package provider
+
+import (
+	"os"
+)
+
+// fileOwnerOf returns nil, as files are not owned by user and group IDs on
+// Windows.
+func fileOwnerOf(info os.FileInfo) *fileOwner {
+	return nil
+}
@@ -5,20 +5,25 @@ package provider
 
 import (
 	"context"
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
-	"encoding/base64"
-	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
@@ -32,12 +37,148 @@ func New() provider.Provider {
 
 type localProvider struct{}
 
+type localProviderModel struct {
+	AllowedPaths    types.List `tfsdk:"allowed_paths"`
+	AllowedCommands types.List `tfsdk:"allowed_commands"`
+	DeniedCommands  types.List `tfsdk:"denied_commands"`
+}
+
+// localProviderData is handed to every resource, data source, action and
+// ephemeral resource once the provider has been configured. A nil
+// *localProviderData places no restrictions on what they may do.
+type localProviderData struct {
+	// allowedPaths holds the resolved form of each entry in the
+	// allowed_paths provider attribute. When nil, any path is allowed.
+	allowedPaths []string
+
+	// allowedCommands and deniedCommands hold the patterns of the
+	// allowed_commands and denied_commands provider attributes. When
+	// allowedCommands is nil, any command that is not denied is allowed.
+	allowedCommands []string
+	deniedCommands  []string
+
+	// unknown is set when any of the restrictions above could not be
+	// determined because the provider configuration is not yet known.
+	unknown bool
+}
+
 func (p *localProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "local"
 }
 
 func (p *localProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config localProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := &localProviderData{}
+
+	// Unknown values can only occur during planning. Rather than skipping the
+	// affected restriction, every path or command is then rejected until the
+	// values are known, as data sources and ephemeral resources act during plan.
+	for _, list := range []types.List{config.AllowedPaths, config.AllowedCommands, config.DeniedCommands} {
+		if list.IsUnknown() {
+			data.unknown = true
+		}
+
+		for _, element := range list.Elements() {
+			if element.IsUnknown() {
+				data.unknown = true
+			}
+		}
+	}
+
+	if data.unknown {
+		resp.DataSourceData = data
+		resp.ResourceData = data
+		resp.ActionData = data
+		resp.EphemeralResourceData = data
+		return
+	}
+
+	allowedPaths, diags := listValueStrings(ctx, config.AllowedPaths)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if allowedPaths != nil {
+		data.allowedPaths = make([]string, 0, len(allowedPaths))
+		for _, allowedPath := range allowedPaths {
+			resolvedPath, err := resolvePath(allowedPath)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("allowed_paths"),
+					"Invalid Allowed Path",
+					"The provider received an unexpected error while attempting to resolve an allowed path.\n\n"+
+						fmt.Sprintf("Path: %s\n", allowedPath)+
+						fmt.Sprintf("Error: %s", err),
+				)
+				return
+			}
+
+			data.allowedPaths = append(data.allowedPaths, resolvedPath)
+		}
+	}
+
+	for _, commands := range []struct {
+		attributeName string
+		list          types.List
+		patterns      *[]string
+	}{
+		{"allowed_commands", config.AllowedCommands, &data.allowedCommands},
+		{"denied_commands", config.DeniedCommands, &data.deniedCommands},
+	} {
+		values, diags := listValueStrings(ctx, commands.list)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		for _, pattern := range values {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(commands.attributeName),
+					"Invalid Command Pattern",
+					"The provider received an invalid glob pattern. Patterns use the syntax of the Go path/filepath.Match function.\n\n"+
+						fmt.Sprintf("Pattern: %s\n", pattern)+
+						fmt.Sprintf("Error: %s", err),
+				)
+				return
+			}
+		}
+
+		*commands.patterns = values
+	}
 
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.ActionData = data
+	resp.EphemeralResourceData = data
+}
+
+// listValueStrings returns the elements of a known list of strings, or a nil
+// slice if the list is null.
+func listValueStrings(ctx context.Context, list types.List) ([]string, diag.Diagnostics) {
+	if list.IsNull() {
+		return nil, nil
+	}
+
+	var elements []types.String
+	diags := list.ElementsAs(ctx, &elements, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		values = append(values, element.ValueString())
+	}
+
+	return values, diags
 }
 
 func (p *localProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
@@ -52,6 +193,8 @@ func (p *localProvider) Resources(ctx context.Context) []func() resource.Resourc
 	return []func() resource.Resource{
 		NewLocalFileResource,
 		NewLocalSensitiveFileResource,
+		NewLocalCommandResource,
+		NewLocalCommandOutputFileResource,
 	}
 }
 
@@ -74,34 +217,276 @@ func (p *localProvider) EphemeralResources(ctx context.Context) []func() ephemer
 }
 
 func (p *localProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
-	resp.Schema = schema.Schema{}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"allowed_paths": schema.ListAttribute{
+				MarkdownDescription: "Directories that files managed or read by this provider must reside in, either absolute paths or relative " +
+					"to the Terraform working directory. Paths are resolved through symbolic links and `..` elements before being compared, " +
+					"and the check is made during plan, apply and destroy, where a file outside of them is not removed. " +
+					"If not provided, any path accessible to the Terraform process is allowed. " +
+					"If the value is not known during plan, every path is rejected until it is known.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"allowed_commands": schema.ListAttribute{
+				MarkdownDescription: "Commands that the `local_command` data source, action and ephemeral resource are allowed to execute. " +
+					"Each entry is either a command name, such as `jq`, which is matched against the file name of the command, or an absolute path, " +
+					"such as `/usr/bin/jq`, which is matched against the full path the command resolves to. Both forms accept glob patterns, " +
+					"for example `/opt/tools/bin/*`. If not provided, any command that is not denied by `denied_commands` is allowed. " +
+					"If the value is not known during plan, every command is rejected until it is known.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"denied_commands": schema.ListAttribute{
+				MarkdownDescription: "Commands that the `local_command` data source, action and ephemeral resource must not execute, " +
+					"using the same format as `allowed_commands`. A command matching both lists is denied. " +
+					"If the value is not known during plan, every command is rejected until it is known.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+		},
+	}
 }
 
-type fileChecksums struct {
-	md5Hex       string
-	sha1Hex      string
-	sha256Hex    string
-	sha256Base64 string
-	sha512Hex    string
-	sha512Base64 string
+// checkCommand returns an attribute error diagnostic for attributePath if
+// the given command is denied, or not allowed, by the allowed_commands and
+// denied_commands provider configuration. Commands that cannot be found are
+// left for the caller to report.
+func (d *localProviderData) checkCommand(attributePath path.Path, command string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if d == nil {
+		return diags
+	}
+
+	if d.unknown {
+		diags.Append(unknownProviderConfigDiagnostic(attributePath))
+		return diags
+	}
+
+	if d.allowedCommands == nil && d.deniedCommands == nil {
+		return diags
+	}
+
+	lookupPath, err := exec.LookPath(command)
+	if err != nil {
+		return diags
+	}
+
+	// Both the path found on the PATH and its target are matched, so that a
+	// symbolic link can neither hide a denied command nor be required to
+	// spell out an allowed one.
+	candidates := []string{lookupPath}
+	if absPath, err := filepath.Abs(lookupPath); err == nil {
+		candidates = append(candidates, absPath)
+	}
+	if resolvedPath, err := filepath.EvalSymlinks(lookupPath); err == nil {
+		if absPath, err := filepath.Abs(resolvedPath); err == nil {
+			candidates = append(candidates, absPath)
+		}
+	}
+
+	if pattern, ok := matchCommand(d.deniedCommands, candidates); ok {
+		diags.AddAttributeError(
+			attributePath,
+			"Command Not Allowed",
+			"The command is denied by the denied_commands provider configuration.\n\n"+
+				fmt.Sprintf("Command: %s\n", command)+
+				fmt.Sprintf("Resolved Command: %s\n", candidates[len(candidates)-1])+
+				fmt.Sprintf("Denied By: %s", pattern),
+		)
+		return diags
+	}
+
+	if d.allowedCommands == nil {
+		return diags
+	}
+
+	if _, ok := matchCommand(d.allowedCommands, candidates); !ok {
+		diags.AddAttributeError(
+			attributePath,
+			"Command Not Allowed",
+			"The command is not allowed by the allowed_commands provider configuration.\n\n"+
+				fmt.Sprintf("Command: %s\n", command)+
+				fmt.Sprintf("Resolved Command: %s\n", candidates[len(candidates)-1])+
+				fmt.Sprintf("Allowed Commands: %s", strings.Join(d.allowedCommands, ", ")),
+		)
+	}
+
+	return diags
+}
+
+// matchCommand returns the first pattern matching any of the candidate
+// command paths. Patterns containing a path separator are matched against the
+// full path, while all other patterns are matched against the file name.
+func matchCommand(patterns []string, candidates []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			name := candidate
+			if !strings.ContainsRune(pattern, filepath.Separator) && !strings.ContainsRune(pattern, '/') {
+				name = filepath.Base(candidate)
+			}
+
+			if matched, _ := filepath.Match(filepath.FromSlash(pattern), filepath.FromSlash(name)); matched {
+				return pattern, true
+			}
+		}
+	}
+
+	return "", false
 }
 
-func genFileChecksums(data []byte) fileChecksums {
-	var checksums fileChecksums
+// checkPath returns an attribute error diagnostic for attributePath if the
+// given filesystem path does not resolve to a location beneath one of the
+// allowed paths of the provider configuration.
+func (d *localProviderData) checkPath(attributePath path.Path, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-	md5Sum := md5.Sum(data)
-	checksums.md5Hex = hex.EncodeToString(md5Sum[:])
+	if d == nil {
+		return diags
+	}
+
+	if d.unknown {
+		diags.Append(unknownProviderConfigDiagnostic(attributePath))
+		return diags
+	}
 
-	sha1Sum := sha1.Sum(data)
-	checksums.sha1Hex = hex.EncodeToString(sha1Sum[:])
+	if d.allowedPaths == nil {
+		return diags
+	}
 
-	sha256Sum := sha256.Sum256(data)
-	checksums.sha256Hex = hex.EncodeToString(sha256Sum[:])
-	checksums.sha256Base64 = base64.StdEncoding.EncodeToString(sha256Sum[:])
+	resolvedPath, err := resolvePath(name)
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Path Resolution Failed",
+			"An unexpected error occurred while resolving the path against the allowed_paths provider configuration.\n\n"+
+				fmt.Sprintf("Path: %s\n", name)+
+				fmt.Sprintf("Error: %s", err),
+		)
+		return diags
+	}
 
-	sha512Sum := sha512.Sum512(data)
-	checksums.sha512Hex = hex.EncodeToString(sha512Sum[:])
-	checksums.sha512Base64 = base64.StdEncoding.EncodeToString(sha512Sum[:])
+	for _, allowedPath := range d.allowedPaths {
+		if pathWithin(allowedPath, resolvedPath) {
+			return diags
+		}
+	}
+
+	diags.AddAttributeError(
+		attributePath,
+		"Path Not Allowed",
+		"The path is outside of the directories allowed by the allowed_paths provider configuration.\n\n"+
+			fmt.Sprintf("Path: %s\n", name)+
+			fmt.Sprintf("Resolved Path: %s\n", resolvedPath)+
+			fmt.Sprintf("Allowed Paths: %s", strings.Join(d.allowedPaths, ", ")),
+	)
+
+	return diags
+}
+
+func unknownProviderConfigDiagnostic(attributePath path.Path) diag.Diagnostic {
+	return diag.NewAttributeErrorDiagnostic(
+		attributePath,
+		"Unknown Provider Configuration",
+		"The allowed_paths, allowed_commands or denied_commands provider configuration depends on values that are not yet known, "+
+			"so it cannot be verified whether this value is allowed. Ensure these provider attributes only refer to values known during plan.",
+	)
+}
+
+// resolvePath returns the absolute form of name with all symbolic links and
+// ".." elements resolved. Trailing elements which do not exist yet, such as
+// a file that is about to be created, are joined lexically onto the resolved
+// form of the deepest existing ancestor.
+func resolvePath(name string) (string, error) {
+	return resolvePathWithLinks(name, 0)
+}
+
+// maxSymlinks mirrors the limit most Unix kernels place on the number of
+// symbolic links followed while resolving a single path.
+const maxSymlinks = 40
+
+func resolvePathWithLinks(name string, links int) (string, error) {
+	if !filepath.IsAbs(name) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+
+		// Joining by hand rather than with filepath.Join, which would clean
+		// ".." elements lexically before any symbolic links are resolved.
+		name = wd + string(filepath.Separator) + name
+	}
+
+	volume := filepath.VolumeName(name)
+	resolved := volume + string(filepath.Separator)
+
+	for _, element := range strings.Split(name[len(volume):], string(filepath.Separator)) {
+		if element == "" || element == "." {
+			continue
+		}
+
+		// The resolved path never contains symbolic links, so a ".." element
+		// can be safely cleaned lexically when joined onto it.
+		candidate := filepath.Join(resolved, element)
+
+		evaluated, err := filepath.EvalSymlinks(candidate)
+		if err == nil {
+			resolved = evaluated
+			continue
+		}
+
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		// A dangling symbolic link would be followed when the file is
+		// written, so its target must be resolved rather than the link.
+		info, lstatErr := os.Lstat(candidate)
+		if lstatErr != nil || info.Mode()&os.ModeSymlink == 0 {
+			resolved = candidate
+			continue
+		}
+
+		if links >= maxSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links: %s", candidate)
+		}
+
+		target, err := os.Readlink(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if !filepath.IsAbs(target) {
+			target = resolved + string(filepath.Separator) + target
+		}
+
+		resolved, err = resolvePathWithLinks(target, links+1)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return resolved, nil
+}
+
+// pathWithin reports whether target is equal to or beneath parent. Both are
+// expected to have been resolved with resolvePath.
+func pathWithin(parent, target string) bool {
+	rel, err := filepath.Rel(parent, target)
+	if err != nil {
+		return false
+	}
 
-	return checksums
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
 }
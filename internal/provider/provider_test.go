@@ -4,13 +4,22 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -59,6 +68,36 @@ func checkFileCreation(resourceName, path string) resource.TestCheckFunc {
 	}
 }
 
+func checkFileContent(path, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("Error occurred while reading file at path: %s\n, error: %s\n", path, err)
+		}
+
+		if string(content) != expected {
+			return fmt.Errorf("File content is %q, expected %q", content, expected)
+		}
+
+		return nil
+	}
+}
+
+func checkFileOwner(path string, uid, gid int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("Error occurred while retrieving file info at path: %s\n, error: %s\n", path, err)
+		}
+
+		if owner := fileOwnerOf(info); owner == nil || owner.uid != uid || owner.gid != gid {
+			return fmt.Errorf("File owner is %+v, expected %d:%d", owner, uid, gid)
+		}
+
+		return nil
+	}
+}
+
 func checkFilePermissions(destinationFilePath string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		filePermission := os.FileMode(0600)
@@ -115,3 +154,290 @@ func skipTestsWindows() func() (bool, error) {
 		return false, nil
 	}
 }
+
+func TestConfigureUnknown(t *testing.T) {
+	t.Parallel()
+
+	listType := tftypes.List{ElementType: tftypes.String}
+
+	testCases := map[string]struct {
+		attribute string
+		value     tftypes.Value
+		unknown   bool
+	}{
+		"allowed_paths-unknown": {
+			attribute: "allowed_paths",
+			value:     tftypes.NewValue(listType, tftypes.UnknownValue),
+			unknown:   true,
+		},
+		"allowed_paths-unknown-element": {
+			attribute: "allowed_paths",
+			value:     tftypes.NewValue(listType, []tftypes.Value{tftypes.NewValue(tftypes.String, tftypes.UnknownValue)}),
+			unknown:   true,
+		},
+		"allowed_commands-unknown": {
+			attribute: "allowed_commands",
+			value:     tftypes.NewValue(listType, tftypes.UnknownValue),
+			unknown:   true,
+		},
+		"denied_commands-unknown-element": {
+			attribute: "denied_commands",
+			value:     tftypes.NewValue(listType, []tftypes.Value{tftypes.NewValue(tftypes.String, tftypes.UnknownValue)}),
+			unknown:   true,
+		},
+		"denied_commands-known": {
+			attribute: "denied_commands",
+			value:     tftypes.NewValue(listType, []tftypes.Value{tftypes.NewValue(tftypes.String, "terraform-provider-local-denied")}),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			p := &localProvider{}
+
+			var schemaResp provider.SchemaResponse
+			p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+			objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+			values := map[string]tftypes.Value{}
+			for attribute, attributeType := range objectType.AttributeTypes {
+				values[attribute] = tftypes.NewValue(attributeType, nil)
+			}
+			values[testCase.attribute] = testCase.value
+
+			var resp provider.ConfigureResponse
+			p.Configure(ctx, provider.ConfigureRequest{
+				Config: tfsdk.Config{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(objectType, values),
+				},
+			}, &resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			data := resp.ResourceData.(*localProviderData)
+
+			// Every path and command is rejected while any of the
+			// restrictions is unknown.
+			for _, diags := range [][]string{
+				diagSummaries(data.checkPath(tfpath.Root("filename"), t.TempDir())),
+				diagSummaries(data.checkCommand(tfpath.Root("command"), "sh")),
+			} {
+				if testCase.unknown && (len(diags) != 1 || diags[0] != "Unknown Provider Configuration") {
+					t.Errorf("expected Unknown Provider Configuration error, got: %v", diags)
+				}
+
+				if !testCase.unknown && len(diags) != 0 {
+					t.Errorf("unexpected diagnostics: %v", diags)
+				}
+			}
+		})
+	}
+}
+
+// diagSummaries returns the summaries of the diagnostics.
+func diagSummaries(diags diag.Diagnostics) []string {
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary())
+	}
+
+	return summaries
+}
+
+func TestResolvePath(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+
+	// The temporary directory may itself be behind a symbolic link, such as
+	// /tmp on macOS, so resolve it before building the expectations.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := filepath.Join(dir, "allowed")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{allowed, outside} {
+		if err := os.Mkdir(d, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(allowed, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		name     string
+		expected string
+	}{
+		"existing": {
+			name:     allowed,
+			expected: allowed,
+		},
+		"not-existing": {
+			name:     filepath.Join(allowed, "new", "file"),
+			expected: filepath.Join(allowed, "new", "file"),
+		},
+		"dot-dot": {
+			name:     allowed + "/../outside/file",
+			expected: filepath.Join(outside, "file"),
+		},
+		"symlink": {
+			name:     filepath.Join(allowed, "escape", "file"),
+			expected: filepath.Join(outside, "file"),
+		},
+		"symlink-dot-dot": {
+			name:     allowed + "/escape/../allowed/file",
+			expected: filepath.Join(allowed, "file"),
+		},
+		"dangling-symlink": {
+			name:     filepath.Join(allowed, "dangling"),
+			expected: filepath.Join(outside, "missing"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolvePath(testCase.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestPathWithin(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.FromSlash("/srv/allowed")
+
+	testCases := map[string]struct {
+		target   string
+		expected bool
+	}{
+		"same":          {target: "/srv/allowed", expected: true},
+		"child":         {target: "/srv/allowed/file", expected: true},
+		"nested":        {target: "/srv/allowed/a/b/c", expected: true},
+		"dot-dot-name":  {target: "/srv/allowed/..file", expected: true},
+		"parent":        {target: "/srv", expected: false},
+		"sibling":       {target: "/srv/other/file", expected: false},
+		"common-prefix": {target: "/srv/allowed-not/file", expected: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := pathWithin(root, filepath.FromSlash(testCase.target)); got != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{
+		filepath.FromSlash("/usr/bin/sh"),
+		filepath.FromSlash("/usr/bin/dash"),
+	}
+
+	testCases := map[string]struct {
+		patterns []string
+		expected bool
+	}{
+		"name":                 {patterns: []string{"sh"}, expected: true},
+		"resolved-name":        {patterns: []string{"dash"}, expected: true},
+		"name-glob":            {patterns: []string{"da*"}, expected: true},
+		"path":                 {patterns: []string{"/usr/bin/sh"}, expected: true},
+		"path-glob":            {patterns: []string{"/usr/bin/*"}, expected: true},
+		"path-glob-no-nesting": {patterns: []string{"/usr/*"}, expected: false},
+		"other-name":           {patterns: []string{"bash"}, expected: false},
+		"other-path":           {patterns: []string{"/bin/sh"}, expected: false},
+		"empty":                {patterns: nil, expected: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, got := matchCommand(testCase.patterns, candidates); got != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestDeleteNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]fwresource.ResourceWithConfigure{
+		"local_file":                &localFileResource{},
+		"local_sensitive_file":      &localSensitiveFileResource{},
+		"local_command_output_file": &localCommandOutputFileResource{},
+	}
+
+	for name, r := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			filename := filepath.Join(t.TempDir(), "file")
+
+			if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			r.Configure(ctx, fwresource.ConfigureRequest{
+				ProviderData: &localProviderData{allowedPaths: []string{t.TempDir()}},
+			}, &fwresource.ConfigureResponse{})
+
+			var schemaResp fwresource.SchemaResponse
+			r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+			objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+			values := map[string]tftypes.Value{}
+			for attribute, attributeType := range objectType.AttributeTypes {
+				values[attribute] = tftypes.NewValue(attributeType, nil)
+			}
+			values["filename"] = tftypes.NewValue(tftypes.String, filename)
+
+			var resp fwresource.DeleteResponse
+			r.Delete(ctx, fwresource.DeleteRequest{
+				State: tfsdk.State{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(objectType, values),
+				},
+			}, &resp)
+
+			if diags := diagSummaries(resp.Diagnostics); len(diags) != 1 || diags[0] != "Path Not Allowed" {
+				t.Errorf("expected Path Not Allowed error, got: %v", diags)
+			}
+
+			// The file outside of the allowed paths is kept.
+			if _, err := os.Stat(filename); err != nil {
+				t.Errorf("expected file to be kept, got: %s", err)
+			}
+		})
+	}
+}
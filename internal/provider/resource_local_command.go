@@ -0,0 +1,865 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
+)
+
+var (
+	_ resource.Resource                   = (*localCommandResource)(nil)
+	_ resource.ResourceWithConfigure      = (*localCommandResource)(nil)
+	_ resource.ResourceWithModifyPlan     = (*localCommandResource)(nil)
+	_ resource.ResourceWithValidateConfig = (*localCommandResource)(nil)
+)
+
+func NewLocalCommandResource() resource.Resource {
+	return &localCommandResource{}
+}
+
+type localCommandResource struct {
+	providerData *localProviderData
+}
+
+func (r *localCommandResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *localCommandResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_command"
+}
+
+func (r *localCommandResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the lifecycle of an object outside of Terraform with executables on the local machine. The `create` command runs when the resource is created, " +
+			"the `read` command runs whenever Terraform refreshes the resource, the `update` command runs when the `create` block changes, and the `destroy` command runs " +
+			"when the resource is destroyed. The standard output data (`stdout`) and exit code of the `create` command are persisted in state, and `stdout` is passed to " +
+			"the standard input of the `read`, `update` and `destroy` commands unless they set `stdin`, so that they can refer to the object that was created. " +
+			"If the `create` command fails, its outcome is still persisted and the resource is marked as tainted, so that it is replaced on the next apply. " +
+			"This replaces the `null_resource` and `local-exec` provisioner pattern." +
+			"\n\n" +
+			"Alternatively, set `protocol` to `json` to implement a resource as a program. Each command is then passed a JSON document on its standard input, " +
+			"with the `operation` (`plan`, `create`, `read`, `update` or `delete`), the `config` from `input`, the `prior_state` and `planned_state`, and the `private` data " +
+			"returned by the previous operation. Each command must write a JSON document to its standard output, with the new `state` of the object, " +
+			"`private` data to be passed to subsequent operations, and `diagnostics`, a list of objects with a `severity` (`error` or `warning`), `summary` and `detail`. " +
+			"All fields are optional, and empty output is treated as an empty document. " +
+			"The `state` is available in the `state` attribute, while `stdout` is `null` so that `private` data is not persisted, and a `null` state returned by the `read` command indicates that the object no longer exists. " +
+			"The `plan` command runs whenever Terraform plans the resource. It can validate `input` and return `requires_replace`, and its `state`, if not `null`, is the planned state " +
+			"that `create` and `update` must return. A planned state that differs from the prior state, such as when the object was changed outside of Terraform, is applied by the `update` command." +
+			"\n\n" +
+			"Any non-zero exit code returned by the `create`, `update` or `destroy` commands will be treated as an error and will return a diagnostic to Terraform " +
+			"containing the `stderr` message if available. A non-zero exit code returned by the `read` command indicates that the object no longer exists, " +
+			"and Terraform will plan to create it again." +
+			"\n\n" +
+			"~> **Warning** This mechanism is provided as an \"escape hatch\" for exceptional situations where a first-class Terraform provider is not more appropriate. " +
+			"Its capabilities are limited in comparison to a true resource, and implementing a resource via a local executable is likely to hurt the " +
+			"portability of your Terraform configuration by creating dependencies on external programs and libraries that may not be available (or may need to be used differently) " +
+			"on different operating systems." +
+			"\n\n" +
+			"~> **Warning** HCP Terraform and Terraform Enterprise do not guarantee availability of any particular language runtimes or external programs beyond standard shell utilities, " +
+			"so it is not recommended to use this resource within configurations that are applied within either.",
+		Attributes: map[string]schema.Attribute{
+			"triggers": schema.MapAttribute{
+				Description: "Arbitrary values that, when changed, force the resource to be replaced, running the `destroy` command followed by the `create` command.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"exit_code": schema.Int64Attribute{
+				Description: "The exit code returned by the `create` command, or by the `update` command if it has run since.",
+				Computed:    true,
+			},
+			"stdout": schema.StringAttribute{
+				MarkdownDescription: "Data returned from the standard output stream of the `create` command, or of the `update` command if it has run since. " +
+					"If the `read` command is configured, its standard output replaces this value whenever the resource is refreshed. " +
+					"The data is returned directly from the command as a UTF-8 string, which can then be decoded by any Terraform decode function, " +
+					"for example, [`jsondecode`](https://developer.hashicorp.com/terraform/language/functions/jsondecode). " +
+					"Always `null` when `protocol` is `json`, as the standard output is then the response document, which may hold `private` data; use `state` instead.",
+				Computed: true,
+			},
+			"stderr": schema.StringAttribute{
+				Description: "Data returned from the standard error stream of the `create` command, or of the `update` command if it has run since. " +
+					"The data is returned directly from the command as a UTF-8 string.",
+				Computed: true,
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "How the commands are passed their input and return their output. Valid values are `raw` and `json`. " +
+					"With `raw`, commands are passed `stdin` and their standard output is returned as is. With `json`, commands are passed a JSON request document " +
+					"and must return a JSON response document, as described above. Changing this forces the resource to be replaced. Defaults to `raw`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(localcommand.Protocols()...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"input": schema.DynamicAttribute{
+				MarkdownDescription: "Arbitrary value passed to the commands as `config` of the request document. Without an `update` block, changing this forces the resource to be replaced. " +
+					"Can only be set when `protocol` is `json`.",
+				Optional: true,
+			},
+			"state": schema.DynamicAttribute{
+				MarkdownDescription: "The `state` returned by the commands in their response document when `protocol` is `json`, otherwise `null`.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Description: "A random identifier generated when the resource is created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"create": localCommandResourceBlock(
+				"The command that creates the object. Required. Without an `update` block, changing this block forces the resource to be replaced.",
+				objectvalidator.IsRequired(),
+			),
+			"read": localCommandResourceBlock(
+				"The command that reads the object whenever Terraform refreshes the resource. A non-zero exit code indicates that the object no longer exists. " +
+					"If not provided, the resource is not refreshed.",
+			),
+			"update": localCommandResourceBlock(
+				"The command that updates the object in place when the `create` block or `input` changes, or when the `plan` command plans a different state. " +
+					"If not provided, the resource is replaced instead.",
+			),
+			"destroy": localCommandResourceBlock(
+				"The command that destroys the object. The command configured when the resource was last applied is used. If not provided, destroying the resource only removes it from state.",
+			),
+			"plan": localCommandResourceBlock(
+				"The command that plans changes to the object whenever Terraform plans the resource, once `input` is known. Can only be set when `protocol` is `json`.",
+			),
+		},
+	}
+}
+
+// localCommandResourceBlock returns the schema of a block that configures one
+// of the commands of the local_command resource.
+func localCommandResourceBlock(description string, validators ...validator.Object) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: description,
+		Validators:          validators,
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
+			"command": schema.StringAttribute{
+				Description: "Executable name to be discovered on the PATH or absolute path to executable. Must be set when the block is configured.",
+				Optional:    true,
+			},
+			"stdin": schema.StringAttribute{
+				MarkdownDescription: "Data to be passed to the given command's standard input as a UTF-8 string. If not provided, the `read`, `update` and `destroy` commands " +
+					"are passed `stdout` of the resource. Cannot be set when `protocol` is `json`.",
+				Optional: true,
+			},
+		}, resourceCommandAttributes(false)),
+		Blocks: map[string]schema.Block{
+			"limits":  resourceLimitsBlock(),
+			"sandbox": resourceSandboxBlock(),
+		},
+	}
+}
+
+// localCommandResourceBlocks are the names of the command blocks of the
+// local_command resource.
+var localCommandResourceBlocks = []string{"create", "read", "update", "destroy", "plan"}
+
+func (r *localCommandResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var protocol types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("protocol"), &protocol)...)
+
+	var input types.Dynamic
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("input"), &input)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if protocol.IsUnknown() {
+		return
+	}
+
+	jsonProtocol := localcommand.Protocol(protocol.ValueString()) == localcommand.ProtocolJSON
+
+	if !jsonProtocol && !input.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("input"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("The \"input\" attribute can only be configured when \"protocol\" is set to %q.", localcommand.ProtocolJSON),
+		)
+	}
+
+	for _, name := range localCommandResourceBlocks {
+		var block types.Object
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), &block)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if block.IsNull() || block.IsUnknown() {
+			continue
+		}
+
+		var model localCommandResourceCommandModel
+		resp.Diagnostics.Append(block.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// The command is optional in the schema, as attributes of an absent
+		// block cannot be required, so it is required here instead.
+		if model.Command.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name).AtName("command"),
+				"Missing Attribute Configuration",
+				fmt.Sprintf("The \"command\" attribute must be configured in the %q block.", name),
+			)
+		}
+
+		if name == "plan" && !jsonProtocol {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("The \"plan\" block can only be configured when \"protocol\" is set to %q.", localcommand.ProtocolJSON),
+			)
+		}
+
+		// The standard input of the command is the request document.
+		if jsonProtocol && !model.Stdin.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name).AtName("stdin"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("The \"stdin\" attribute cannot be configured when \"protocol\" is set to %q.", localcommand.ProtocolJSON),
+			)
+		}
+
+		resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Root(name), model.InheritEnvironment, model.InheritedEnvironmentVariables)...)
+		resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Root(name), model.RunAsUser, model.RunAsGroup)...)
+		resp.Diagnostics.Append(localcommand.ValidateLimits(path.Root(name), model.Limits.limits())...)
+		resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Root(name), model.Sandbox.sandbox())...)
+	}
+}
+
+type localCommandResourceModel struct {
+	ID       types.String  `tfsdk:"id"`
+	Triggers types.Map     `tfsdk:"triggers"`
+	Protocol types.String  `tfsdk:"protocol"`
+	Input    types.Dynamic `tfsdk:"input"`
+	Create   types.Object  `tfsdk:"create"`
+	Read     types.Object  `tfsdk:"read"`
+	Update   types.Object  `tfsdk:"update"`
+	Destroy  types.Object  `tfsdk:"destroy"`
+	Plan     types.Object  `tfsdk:"plan"`
+	ExitCode types.Int64   `tfsdk:"exit_code"`
+	Stdout   types.String  `tfsdk:"stdout"`
+	Stderr   types.String  `tfsdk:"stderr"`
+	State    types.Dynamic `tfsdk:"state"`
+}
+
+// json reports whether the commands use the JSON request and response
+// protocol.
+func (m *localCommandResourceModel) json() bool {
+	return localcommand.Protocol(m.Protocol.ValueString()) == localcommand.ProtocolJSON
+}
+
+// changed reports whether the object must be updated, or replaced if there
+// is no update command, to apply the model over the prior model.
+func (m *localCommandResourceModel) changed(prior localCommandResourceModel) bool {
+	return !m.Create.Equal(prior.Create) || !m.Input.Equal(prior.Input)
+}
+
+type localCommandResourceCommandModel struct {
+	Command                       types.String              `tfsdk:"command"`
+	Arguments                     types.List                `tfsdk:"arguments"`
+	Stdin                         types.String              `tfsdk:"stdin"`
+	WorkingDirectory              types.String              `tfsdk:"working_directory"`
+	Timeout                       localtypes.DurationValue  `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue  `tfsdk:"termination_grace_period"`
+	Environment                   types.Map                 `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                 `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String              `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String              `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String              `tfsdk:"run_as_group"`
+	Limits                        *localCommandLimitsModel  `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel `tfsdk:"sandbox"`
+}
+
+// runAs returns the user and group the command runs as.
+func (m *localCommandResourceCommandModel) runAs() localcommand.RunAs {
+	return localcommand.RunAs{
+		User:  m.RunAsUser.ValueString(),
+		Group: m.RunAsGroup.ValueString(),
+	}
+}
+
+func (r *localCommandResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing is run when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan localCommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, name := range localCommandResourceBlocks {
+		var block types.Object
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(name), &block)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if block.IsNull() || block.IsUnknown() {
+			continue
+		}
+
+		var command localCommandResourceCommandModel
+		resp.Diagnostics.Append(block.As(ctx, &command, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if command.Command.IsNull() || command.Command.IsUnknown() || command.RunAsUser.IsUnknown() || command.RunAsGroup.IsUnknown() {
+			continue
+		}
+
+		resp.Diagnostics.Append(findCommand(r.providerData, path.Root(name).AtName("command"), localcommand.KindResource, command.Command.ValueString(), command.runAs())...)
+	}
+
+	var state localCommandResourceModel
+	changed := true
+
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		changed = plan.changed(state)
+
+		if !changed {
+			plan.ExitCode = state.ExitCode
+			plan.Stdout = state.Stdout
+			plan.Stderr = state.Stderr
+			plan.State = state.State
+		} else if plan.Update.IsNull() {
+			// The update command runs only when the create command or input
+			// changes, and the resource is replaced instead if there is no
+			// update command.
+			if !plan.Create.Equal(state.Create) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("create"))
+			}
+
+			if !plan.Input.Equal(state.Input) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("input"))
+			}
+		}
+	} else {
+		state.State = types.DynamicNull()
+	}
+
+	if !plan.json() {
+		plan.State = types.DynamicNull()
+	} else {
+		// The standard output of the commands is the response, which may
+		// hold private data, so only the state it returns is persisted.
+		plan.Stdout = types.StringNull()
+
+		if !plan.Plan.IsNull() {
+			resp.Diagnostics.Append(r.plan(ctx, req, resp, &plan, state)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			// A planned state that differs from the prior state, such as when the
+			// object was changed outside of Terraform, is applied by the update
+			// command, or by replacing the resource if there is no update command.
+			if !changed && !plan.State.Equal(state.State) {
+				plan.ExitCode = types.Int64Unknown()
+				plan.Stderr = types.StringUnknown()
+
+				if plan.Update.IsNull() {
+					resp.RequiresReplace = append(resp.RequiresReplace, path.Root("state"))
+				}
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// plan runs the plan command, setting the planned state of the object if the
+// command returns it. The plan command cannot be passed unknown values, so it
+// only runs once the input is known.
+func (r *localCommandResource) plan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, plan *localCommandResourceModel, state localCommandResourceModel) diag.Diagnostics {
+	input, err := plan.Input.ToTerraformValue(ctx)
+	if err != nil || !input.IsFullyKnown() {
+		return nil
+	}
+
+	private, diags := req.Private.GetKey(ctx, localCommandResourcePrivateKey)
+	if diags.HasError() {
+		return diags
+	}
+
+	_, response, operationDiags := r.operation(ctx, path.Root("plan"), plan.Plan, localcommand.Request{
+		Operation:    localcommand.OperationPlan,
+		Config:       plan.Input,
+		PriorState:   state.State,
+		PlannedState: types.DynamicNull(),
+		Private:      private,
+	})
+	diags.Append(operationDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if response.RequiresReplace && !req.State.Raw.IsNull() {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("input"))
+	}
+
+	if !response.State.IsNull() {
+		plan.State = response.State
+	}
+
+	return diags
+}
+
+func (r *localCommandResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan localCommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		resp.Diagnostics.AddError(
+			"Create local command error",
+			"An unexpected error occurred while generating the resource identifier\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(hex.EncodeToString(id))
+
+	var result *localcommand.Result
+	var diags diag.Diagnostics
+
+	if plan.json() {
+		var response *localcommand.Response
+		result, response, diags = r.operation(ctx, path.Root("create"), plan.Create, localcommand.Request{
+			Operation:    localcommand.OperationCreate,
+			Config:       plan.Input,
+			PriorState:   types.DynamicNull(),
+			PlannedState: plannedState(plan.State),
+		})
+		if diags.HasError() {
+			// A command reporting errors in its response may still return
+			// the state of what it created.
+			if response != nil {
+				plan.State = response.State
+				resp.Diagnostics.Append(setPrivate(ctx, resp.Private, response)...)
+			}
+
+			r.createFailed(ctx, plan, result, diags, resp)
+			return
+		}
+		resp.Diagnostics.Append(diags...)
+
+		plan.State = response.State
+		resp.Diagnostics.Append(setPrivate(ctx, resp.Private, response)...)
+	} else {
+		var command *localcommand.Command
+		command, diags = r.command(ctx, path.Root("create"), plan.Create, types.StringNull())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		result, diags = r.run(ctx, command)
+		if diags.HasError() {
+			r.createFailed(ctx, plan, result, diags, resp)
+			return
+		}
+		resp.Diagnostics.Append(diags...)
+
+		plan.State = types.DynamicNull()
+	}
+
+	plan.setResult(result)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// createFailed adds the diagnostics of a failed create command. If the
+// command was started, it may have created something before failing, so the
+// resource is saved with the outcome of the command first, which Terraform
+// then marks as tainted to be replaced on the next apply.
+func (r *localCommandResource) createFailed(ctx context.Context, plan localCommandResourceModel, result *localcommand.Result, diags diag.Diagnostics, resp *resource.CreateResponse) {
+	if result != nil && result.Started {
+		plan.setResult(result)
+		if plan.State.IsUnknown() {
+			plan.State = types.DynamicNull()
+		}
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	}
+
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *localCommandResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state localCommandResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Read.IsNull() {
+		return
+	}
+
+	var result *localcommand.Result
+
+	if state.json() {
+		private, diags := req.Private.GetKey(ctx, localCommandResourcePrivateKey)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var response *localcommand.Response
+		result, response, diags = r.operation(ctx, path.Root("read"), state.Read, localcommand.Request{
+			Operation:    localcommand.OperationRead,
+			Config:       state.Input,
+			PriorState:   state.State,
+			PlannedState: types.DynamicNull(),
+			Private:      private,
+		})
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// A null state indicates that the object no longer exists.
+		if response.State.IsNull() {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		state.State = response.State
+		state.Stdout = types.StringNull()
+		resp.Diagnostics.Append(setPrivate(ctx, resp.Private, response)...)
+	} else {
+		command, diags := r.command(ctx, path.Root("read"), state.Read, state.Stdout)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// A non-zero exit code indicates that the object no longer exists,
+		// rather than an error.
+		command.AllowNonZeroExitCode = true
+
+		result, diags = r.run(ctx, command)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if result.ExitCode != 0 {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		state.Stdout = types.StringNull()
+		if len(result.Stdout) > 0 {
+			state.Stdout = types.StringValue(string(result.Stdout))
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *localCommandResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state localCommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the other command blocks have changed, so there is nothing to run.
+	if !plan.changed(state) && plan.State.Equal(state.State) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	var result *localcommand.Result
+	var diags diag.Diagnostics
+
+	if plan.json() {
+		var private []byte
+		private, diags = req.Private.GetKey(ctx, localCommandResourcePrivateKey)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var response *localcommand.Response
+		result, response, diags = r.operation(ctx, path.Root("update"), plan.Update, localcommand.Request{
+			Operation:    localcommand.OperationUpdate,
+			Config:       plan.Input,
+			PriorState:   state.State,
+			PlannedState: plannedState(plan.State),
+			Private:      private,
+		})
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		plan.State = response.State
+		resp.Diagnostics.Append(setPrivate(ctx, resp.Private, response)...)
+	} else {
+		var command *localcommand.Command
+		command, diags = r.command(ctx, path.Root("update"), plan.Update, state.Stdout)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		result, diags = r.run(ctx, command)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	plan.setResult(result)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *localCommandResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state localCommandResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Destroy.IsNull() {
+		return
+	}
+
+	if state.json() {
+		private, diags := req.Private.GetKey(ctx, localCommandResourcePrivateKey)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		_, _, diags = r.operation(ctx, path.Root("destroy"), state.Destroy, localcommand.Request{
+			Operation:    localcommand.OperationDelete,
+			Config:       state.Input,
+			PriorState:   state.State,
+			PlannedState: types.DynamicNull(),
+			Private:      private,
+		})
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	command, diags := r.command(ctx, path.Root("destroy"), state.Destroy, state.Stdout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, diags = r.run(ctx, command)
+	resp.Diagnostics.Append(diags...)
+}
+
+// command returns the command configured by the block at blockPath. If the
+// block does not set stdin, stdout is passed to the command instead.
+func (r *localCommandResource) command(ctx context.Context, blockPath path.Path, block types.Object, stdout types.String) (*localcommand.Command, diag.Diagnostics) {
+	var config localCommandResourceCommandModel
+	diags := block.As(ctx, &config, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	stdin := config.Stdin
+	if stdin.IsNull() {
+		stdin = stdout
+	}
+
+	var stdinData []byte
+	if !stdin.IsNull() {
+		stdinData = []byte(stdin.ValueString())
+	}
+
+	return &localcommand.Command{
+		Kind:                          localcommand.KindResource,
+		Path:                          blockPath,
+		Name:                          config.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(config.Arguments),
+		WorkingDirectory:              config.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(config.Environment),
+		SensitiveEnvironment:          localcommand.Environment(config.SensitiveEnvironment),
+		InheritEnvironment:            localcommand.InheritEnvironment(config.InheritEnvironment.ValueString()),
+		InheritedEnvironmentVariables: localcommand.Strings(config.InheritedEnvironmentVariables),
+		RunAs:                         config.runAs(),
+		Limits:                        config.Limits.limits(),
+		Sandbox:                       config.Sandbox.sandbox(),
+		Stdin:                         stdinData,
+		Timeout:                       config.Timeout.ValueDuration(),
+		TerminationGracePeriod:        config.TerminationGracePeriod.ValueDuration(),
+	}, diags
+}
+
+// run verifies that the command is allowed by the provider configuration and
+// then runs it.
+func (r *localCommandResource) run(ctx context.Context, command *localcommand.Command) (*localcommand.Result, diag.Diagnostics) {
+	diags := findCommand(r.providerData, localcommand.AttributePath(command.Path, "command"), command.Kind, command.Name, command.RunAs)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	result, runDiags := command.Run(ctx)
+	diags.Append(runDiags...)
+
+	return result, diags
+}
+
+// operation runs the command configured by the block at blockPath with the
+// JSON request and response protocol, returning the diagnostics of the
+// response along with any execution errors. The result is returned whenever
+// the command was run, even if it failed.
+func (r *localCommandResource) operation(ctx context.Context, blockPath path.Path, block types.Object, request localcommand.Request) (*localcommand.Result, *localcommand.Response, diag.Diagnostics) {
+	command, diags := r.command(ctx, blockPath, block, types.StringNull())
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+
+	stdin, err := request.Marshal(ctx)
+	if err != nil {
+		diags.AddAttributeError(
+			blockPath,
+			"Invalid Command Request",
+			fmt.Sprintf("The resource was unable to encode the %s request for the command.", request.Operation)+
+				"\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return nil, nil, diags
+	}
+
+	command.Stdin = stdin
+
+	result, runDiags := r.run(ctx, command)
+	diags.Append(runDiags...)
+	if diags.HasError() {
+		return result, nil, diags
+	}
+
+	response, err := localcommand.ParseResponse(ctx, result.Stdout)
+	if err != nil {
+		diags.AddAttributeError(
+			localcommand.AttributePath(blockPath, "command"),
+			"Invalid Command Response",
+			fmt.Sprintf("The resource executed the command but was unable to decode its standard output as a %s response.", request.Operation)+
+				"\n\n"+
+				fmt.Sprintf("Command: %s\n", command.Name)+
+				fmt.Sprintf("Error: %s", err),
+		)
+		return result, nil, diags
+	}
+
+	diags.Append(response.Diagnostics...)
+
+	return result, response, diags
+}
+
+// localCommandResourcePrivateKey is the private state key holding the private
+// data returned by commands using the JSON request and response protocol.
+const localCommandResourcePrivateKey = "command_private"
+
+// privateState is implemented by the private state data of the resource
+// responses.
+type privateState interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// setPrivate stores the private data of the response, if any.
+func setPrivate(ctx context.Context, private privateState, response *localcommand.Response) diag.Diagnostics {
+	if response.Private == nil {
+		return nil
+	}
+
+	return private.SetKey(ctx, localCommandResourcePrivateKey, response.Private)
+}
+
+// plannedState returns the planned state to be passed to a command, which is
+// null if it was not planned by the plan command.
+func plannedState(state types.Dynamic) types.Dynamic {
+	if state.IsUnknown() {
+		return types.DynamicNull()
+	}
+
+	return state
+}
+
+// setResult sets the persisted output of the create or update command. With
+// the JSON protocol, the standard output is the response, which is not
+// persisted as it may hold private data.
+func (m *localCommandResourceModel) setResult(result *localcommand.Result) {
+	m.ExitCode = types.Int64Value(int64(result.ExitCode))
+
+	m.Stdout = types.StringNull()
+	if len(result.Stdout) > 0 && !m.json() {
+		m.Stdout = types.StringValue(string(result.Stdout))
+	}
+
+	m.Stderr = types.StringNull()
+	if len(result.Stderr) > 0 {
+		m.Stderr = types.StringValue(string(result.Stderr))
+	}
+}
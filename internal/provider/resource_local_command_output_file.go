@@ -0,0 +1,679 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terraform-providers/terraform-provider-local/internal/localcommand"
+	"github.com/terraform-providers/terraform-provider-local/internal/localtypes"
+)
+
+var (
+	_ resource.Resource                   = (*localCommandOutputFileResource)(nil)
+	_ resource.ResourceWithConfigure      = (*localCommandOutputFileResource)(nil)
+	_ resource.ResourceWithModifyPlan     = (*localCommandOutputFileResource)(nil)
+	_ resource.ResourceWithValidateConfig = (*localCommandOutputFileResource)(nil)
+)
+
+func NewLocalCommandOutputFileResource() resource.Resource {
+	return &localCommandOutputFileResource{}
+}
+
+type localCommandOutputFileResource struct {
+	providerData *localProviderData
+}
+
+func (r *localCommandOutputFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *localCommandOutputFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_command_output_file"
+}
+
+func (r *localCommandOutputFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Generates a local file with the standard output of an executable on the local machine, such as a code generator or an archiving tool. " +
+			"The output is streamed directly to the file, so unlike passing `stdout` of the `local_command` data source to the `content` of `local_file`, " +
+			"it is not stored in state and may be binary. Only the checksums of the file content are stored in state. " +
+			"If the file is removed outside of Terraform, the command is run again, and what happens when it is modified is controlled by `drift_policy`." +
+			"\n\n" +
+			"Any non-zero exit code returned by the command will be treated as an error and will return a diagnostic to Terraform containing the `stderr` message if available. " +
+			"The output is written to a temporary file which only replaces the file once the command succeeds, so a failed command leaves any previous file intact.",
+		Attributes: localCommandAttributes(map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path to the file that will be created.\n " +
+					"Missing parent directories will be created.\n " +
+					"If the file already exists, it will be overridden with the output of the command.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"file_permission": schema.StringAttribute{
+				CustomType: localtypes.NewFilePermissionType(),
+				Description: "Permissions to set for the output file (before umask), expressed as string in\n " +
+					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).\n " +
+					"Changing it changes the permissions of the file in place.\n " +
+					"Default value is `\"0777\"`.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					filePermissionDefault(localFileDefaultPermission),
+				},
+			},
+			"directory_permission": schema.StringAttribute{
+				CustomType: localtypes.NewFilePermissionType(),
+				Description: "Permissions to set for directories created (before umask), expressed as string in\n " +
+					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).\n " +
+					"Changing it does not change the permissions of existing directories.\n " +
+					"Default value is `\"0777\"`.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					filePermissionDefault(localFileDefaultPermission),
+				},
+			},
+			"drift_policy": schema.StringAttribute{
+				Description: "What happens when refreshing finds that the file was changed outside of Terraform.\n " +
+					"The checksums and `actual_file_permission` of the file on disk are always recorded,\n " +
+					"so the changes are shown by the plan, while `id` remains the SHA1 checksum of the content written by Terraform.\n " +
+					"With `recreate`, the command is run again to rewrite changed content and changed permissions are changed back by an in-place update,\n " +
+					"for which the permissions of the file are recorded as its `file_permission`.\n " +
+					"With `ignore`, the changed file is kept.\n " +
+					"With `error`, refreshing fails, including when the file was deleted, which is otherwise created again.\n " +
+					"The policy in the state is used when refreshing, so changing it from `error` requires planning with `-refresh=false`.\n " +
+					"Default value is `\"recreate\"`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(driftPolicyRecreate, driftPolicyIgnore, driftPolicyError),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				Description: "Arbitrary values that, when changed, run the command again and replace the file.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"command": schema.StringAttribute{
+				Description: "Executable name to be discovered on the PATH or absolute path to executable.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"stdin": schema.StringAttribute{
+				Description: "Data to be passed to the given command's standard input as a UTF-8 string.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"id": schema.StringAttribute{
+				Description: "The hexadecimal encoding of the SHA1 checksum of the file content written by Terraform.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_md5": schema.StringAttribute{
+				Description: "MD5 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_sha1": schema.StringAttribute{
+				Description: "SHA1 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_sha256": schema.StringAttribute{
+				Description: "SHA256 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_base64sha256": schema.StringAttribute{
+				Description: "Base64 encoded SHA256 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_sha512": schema.StringAttribute{
+				Description: "SHA512 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_base64sha512": schema.StringAttribute{
+				Description: "Base64 encoded SHA512 checksum of file content.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"actual_file_permission": schema.StringAttribute{
+				Description: "Permissions of the file (after umask), expressed as string in\n " +
+					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		}, resourceCommandAttributes(true)),
+		Blocks: map[string]schema.Block{
+			"limits":  resourceLimitsBlock(objectplanmodifier.RequiresReplace()),
+			"sandbox": resourceSandboxBlock(objectplanmodifier.RequiresReplace()),
+		},
+	}
+}
+
+func (r *localCommandOutputFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var inheritEnvironment types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherit_environment"), &inheritEnvironment)...)
+
+	var inheritedEnvironmentVariables types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inherited_environment_variables"), &inheritedEnvironmentVariables)...)
+
+	var runAsUser, runAsGroup types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_user"), &runAsUser)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("run_as_group"), &runAsGroup)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateInheritEnvironment(path.Empty(), inheritEnvironment, inheritedEnvironmentVariables)...)
+	resp.Diagnostics.Append(localcommand.ValidateRunAs(path.Empty(), runAsUser, runAsGroup)...)
+
+	limits, diags := getConfigBlock[localCommandLimitsModel](ctx, req.Config, path.Root("limits"))
+	resp.Diagnostics.Append(diags...)
+
+	sandbox, diags := getConfigBlock[localCommandSandboxModel](ctx, req.Config, path.Root("sandbox"))
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(localcommand.ValidateLimits(path.Empty(), limits.limits())...)
+	resp.Diagnostics.Append(localcommand.ValidateSandbox(path.Empty(), sandbox.sandbox())...)
+}
+
+type localCommandOutputFileResourceModel struct {
+	Filename                      types.String                   `tfsdk:"filename"`
+	FilePermission                localtypes.FilePermissionValue `tfsdk:"file_permission"`
+	DirectoryPermission           localtypes.FilePermissionValue `tfsdk:"directory_permission"`
+	Triggers                      types.Map                      `tfsdk:"triggers"`
+	Command                       types.String                   `tfsdk:"command"`
+	Arguments                     types.List                     `tfsdk:"arguments"`
+	Stdin                         types.String                   `tfsdk:"stdin"`
+	WorkingDirectory              types.String                   `tfsdk:"working_directory"`
+	Timeout                       localtypes.DurationValue       `tfsdk:"timeout"`
+	TerminationGracePeriod        localtypes.DurationValue       `tfsdk:"termination_grace_period"`
+	Environment                   types.Map                      `tfsdk:"environment"`
+	SensitiveEnvironment          types.Map                      `tfsdk:"sensitive_environment"`
+	InheritEnvironment            types.String                   `tfsdk:"inherit_environment"`
+	InheritedEnvironmentVariables types.List                     `tfsdk:"inherited_environment_variables"`
+	RunAsUser                     types.String                   `tfsdk:"run_as_user"`
+	RunAsGroup                    types.String                   `tfsdk:"run_as_group"`
+	Limits                        *localCommandLimitsModel       `tfsdk:"limits"`
+	Sandbox                       *localCommandSandboxModel      `tfsdk:"sandbox"`
+	DriftPolicy                   types.String                   `tfsdk:"drift_policy"`
+	ID                            types.String                   `tfsdk:"id"`
+	ContentMd5                    types.String                   `tfsdk:"content_md5"`
+	ContentSha1                   types.String                   `tfsdk:"content_sha1"`
+	ContentSha256                 types.String                   `tfsdk:"content_sha256"`
+	ContentBase64sha256           types.String                   `tfsdk:"content_base64sha256"`
+	ContentSha512                 types.String                   `tfsdk:"content_sha512"`
+	ContentBase64sha512           types.String                   `tfsdk:"content_base64sha512"`
+	ActualFilePermission          types.String                   `tfsdk:"actual_file_permission"`
+}
+
+func (r *localCommandOutputFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing is written when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan localCommandOutputFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Filename.IsUnknown() {
+		resp.Diagnostics.Append(r.providerData.checkPath(path.Root("filename"), plan.Filename.ValueString())...)
+	}
+
+	if !plan.Command.IsUnknown() && !plan.RunAsUser.IsUnknown() && !plan.RunAsGroup.IsUnknown() {
+		resp.Diagnostics.Append(findCommand(r.providerData, path.Root("command"), localcommand.KindResource, plan.Command.ValueString(), plan.runAs())...)
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state localCommandOutputFileResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Changing the permissions changes them in place.
+	if !plan.FilePermission.Equal(state.FilePermission) {
+		plan.ActualFilePermission = types.StringUnknown()
+
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+
+	// A file which was changed outside of Terraform is rewritten by running
+	// the command again in an in-place update, unless the changes are ignored.
+	if state.drifted() && plan.DriftPolicy.ValueString() != driftPolicyIgnore {
+		plan.ID = types.StringUnknown()
+		plan.ContentMd5 = types.StringUnknown()
+		plan.ContentSha1 = types.StringUnknown()
+		plan.ContentSha256 = types.StringUnknown()
+		plan.ContentBase64sha256 = types.StringUnknown()
+		plan.ContentSha512 = types.StringUnknown()
+		plan.ContentBase64sha512 = types.StringUnknown()
+		plan.ActualFilePermission = types.StringUnknown()
+
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+}
+
+func (r *localCommandOutputFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan localCommandOutputFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan, "Create local command output file error")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *localCommandOutputFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state localCommandOutputFileResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	outputPath := state.Filename.ValueString()
+	status, err := readLocalFileStatus(outputPath)
+	if os.IsNotExist(err) {
+		if state.DriftPolicy.ValueString() == driftPolicyError {
+			resp.Diagnostics.AddError(
+				"Local File Changed Outside of Terraform",
+				fmt.Sprintf("The file %q was deleted outside of Terraform, and \"drift_policy\" is %q.", outputPath, driftPolicyError),
+			)
+			return
+		}
+
+		// If the output file doesn't exist, mark the resource for creation.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read local command output file error",
+			"An unexpected error occurred while reading the file\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return
+	}
+
+	// The permissions are compared with those the file had when it was last
+	// written or refreshed, as they depend on the umask.
+	expectedPerm := state.ActualFilePermission.ValueString()
+	permDrifted := !state.ActualFilePermission.IsNull() && expectedPerm != formatFilePermission(status.perm)
+
+	// Record the file as it is on disk, so that the plan shows any changes
+	// made outside of Terraform. The ID remains the checksum of the content
+	// written by Terraform.
+	state.setStatus(status)
+
+	if (state.drifted() || permDrifted) && state.DriftPolicy.ValueString() == driftPolicyError {
+		resp.Diagnostics.AddError(
+			"Local File Changed Outside of Terraform",
+			fmt.Sprintf("The file %q was changed outside of Terraform, and \"drift_policy\" is %q.", outputPath, driftPolicyError)+
+				"\n\n"+
+				fmt.Sprintf("Expected SHA1: %s\n", state.ID.ValueString())+
+				fmt.Sprintf("Actual SHA1: %s\n", status.checksums.sha1Hex)+
+				fmt.Sprintf("Expected Permissions: %s\n", expectedPerm)+
+				fmt.Sprintf("Actual Permissions: %s", formatFilePermission(status.perm)),
+		)
+		return
+	}
+
+	// Permissions which were changed outside of Terraform are recorded as the
+	// configured permissions, so that they are changed back in place.
+	if permDrifted && state.DriftPolicy.ValueString() != driftPolicyIgnore {
+		state.FilePermission = localtypes.FilePermissionValue{StringValue: types.StringValue(formatFilePermission(status.perm))}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *localCommandOutputFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state localCommandOutputFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The command is run again to rewrite a file which was changed outside
+	// of Terraform, which also creates it with the planned permissions.
+	if state.drifted() && plan.DriftPolicy.ValueString() != driftPolicyIgnore {
+		resp.Diagnostics.Append(r.write(ctx, &plan, "Update local command output file error")...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
+	if !plan.FilePermission.Equal(state.FilePermission) {
+		resp.Diagnostics.Append(r.providerData.checkPath(path.Root("filename"), plan.Filename.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		fileMode := parseFilePermission(plan.FilePermission, localFileDefaultPermission)
+
+		perm, err := chmodLocalFile(plan.Filename.ValueString(), fileMode)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update local command output file error",
+				"An unexpected error occurred while changing the file permissions\n\n"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return
+		}
+
+		plan.ActualFilePermission = types.StringValue(formatFilePermission(perm))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *localCommandOutputFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var filename string
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("filename"), &filename)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A file which is no longer within the allowed paths is not removed.
+	resp.Diagnostics.Append(r.providerData.checkPath(path.Root("filename"), filename)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(
+			"Delete local command output file error",
+			"An unexpected error occurred while removing the file\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+	}
+}
+
+// write runs the command of the plan, streaming its standard output to the
+// destination file, and records the written file in the plan. Errors are
+// reported with the given summary.
+func (r *localCommandOutputFileResource) write(ctx context.Context, plan *localCommandOutputFileResourceModel, summary string) diag.Diagnostics {
+	destination := plan.Filename.ValueString()
+
+	diags := r.providerData.checkPath(path.Root("filename"), destination)
+	if diags.HasError() {
+		return diags
+	}
+
+	var stdin []byte
+	if !plan.Stdin.IsNull() {
+		stdin = []byte(plan.Stdin.ValueString())
+	}
+
+	command := localcommand.Command{
+		Kind:                          localcommand.KindResource,
+		Name:                          plan.Command.ValueString(),
+		Arguments:                     localcommand.Arguments(plan.Arguments),
+		WorkingDirectory:              plan.WorkingDirectory.ValueString(),
+		Environment:                   localcommand.Environment(plan.Environment),
+		SensitiveEnvironment:          localcommand.Environment(plan.SensitiveEnvironment),
+		InheritEnvironment:            localcommand.InheritEnvironment(plan.InheritEnvironment.ValueString()),
+		InheritedEnvironmentVariables: localcommand.Strings(plan.InheritedEnvironmentVariables),
+		RunAs:                         plan.runAs(),
+		Limits:                        plan.Limits.limits(),
+		Sandbox:                       plan.Sandbox.sandbox(),
+		Stdin:                         stdin,
+		Timeout:                       plan.Timeout.ValueDuration(),
+		TerminationGracePeriod:        plan.TerminationGracePeriod.ValueDuration(),
+	}
+
+	diags.Append(findCommand(r.providerData, path.Root("command"), command.Kind, command.Name, command.RunAs)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	filePerm := parseFilePermission(plan.FilePermission, localFileDefaultPermission)
+	dirPerm := parseFilePermission(plan.DirectoryPermission, localFileDefaultPermission)
+
+	output, err := createLocalFile(destination, filePerm, dirPerm)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while creating the file\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	command.Stdout = output
+
+	_, runDiags := command.Run(ctx)
+	diags.Append(runDiags...)
+	diags.Append(output.close(runDiags.HasError())...)
+	if diags.HasError() {
+		return diags
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while reading the file permissions\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	plan.setStatus(&localFileStatus{
+		checksums: output.checksummer.checksums(),
+		perm:      info.Mode().Perm(),
+	})
+	plan.ID = plan.ContentSha1
+
+	return diags
+}
+
+// runAs returns the user and group the command runs as.
+func (m *localCommandOutputFileResourceModel) runAs() localcommand.RunAs {
+	return localcommand.RunAs{
+		User:  m.RunAsUser.ValueString(),
+		Group: m.RunAsGroup.ValueString(),
+	}
+}
+
+// setStatus records the file as read from disk.
+func (m *localCommandOutputFileResourceModel) setStatus(status *localFileStatus) {
+	m.ContentMd5 = types.StringValue(status.checksums.md5Hex)
+	m.ContentSha1 = types.StringValue(status.checksums.sha1Hex)
+	m.ContentSha256 = types.StringValue(status.checksums.sha256Hex)
+	m.ContentBase64sha256 = types.StringValue(status.checksums.sha256Base64)
+	m.ContentSha512 = types.StringValue(status.checksums.sha512Hex)
+	m.ContentBase64sha512 = types.StringValue(status.checksums.sha512Base64)
+	m.ActualFilePermission = types.StringValue(formatFilePermission(status.perm))
+}
+
+// drifted reports whether the content of the file, as last read from disk,
+// differs from the content written by Terraform.
+func (m *localCommandOutputFileResourceModel) drifted() bool {
+	return !m.ContentSha1.IsNull() && !m.ContentSha1.IsUnknown() && m.ContentSha1.ValueString() != m.ID.ValueString()
+}
+
+// localFileWriter streams the standard output of a command to a temporary
+// file and computes the checksums of the content written. The temporary file
+// replaces the destination file once the command succeeded.
+type localFileWriter struct {
+	destination string
+	file        *os.File
+	checksummer *fileChecksummer
+}
+
+var _ localcommand.OutputWriter = (*localFileWriter)(nil)
+
+// createLocalFile creates a temporary file next to the destination file with
+// the same permission handling as the local_file resource, creating any
+// missing parent directories.
+func createLocalFile(destination string, filePerm, dirPerm os.FileMode) (*localFileWriter, error) {
+	destinationDir := filepath.Dir(destination)
+	if _, err := os.Stat(destinationDir); err != nil {
+		if err := os.MkdirAll(destinationDir, dirPerm); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := createTempFile(destination, filePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localFileWriter{
+		destination: destination,
+		file:        file,
+		checksummer: newFileChecksummer(),
+	}, nil
+}
+
+func (w *localFileWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	_, _ = w.checksummer.Write(p[:n])
+
+	return n, err
+}
+
+func (w *localFileWriter) Reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	w.checksummer.Reset()
+
+	return nil
+}
+
+// close closes the temporary file and renames it to the destination file, or
+// removes it if the command failed so that partial output never replaces the
+// previous file.
+func (w *localFileWriter) close(failed bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var err error
+	if failed {
+		_ = w.file.Close()
+		err = os.Remove(w.file.Name())
+	} else {
+		err = w.rename()
+	}
+
+	if err != nil {
+		diags.AddError(
+			"Write local command output file error",
+			"An unexpected error occurred while writing the file\n\n"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+	}
+
+	return diags
+}
+
+// rename flushes and closes the temporary file, then renames it to the
+// destination file. The temporary file is removed if any of these fail.
+func (w *localFileWriter) rename() error {
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = w.file.Close()
+			_ = os.Remove(w.file.Name())
+		}
+	}()
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(w.file.Name(), w.destination); err != nil {
+		return err
+	}
+
+	renamed = true
+
+	return nil
+}
@@ -0,0 +1,320 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestLocalCommandOutputFile_basic(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "new", "test_file.txt")
+	checksums := genFileChecksums([]byte("hello"))
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		CheckDestroy:             checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigLocalCommandOutputFile(testFile),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("id"), knownvalue.StringExact(checksums.sha1Hex)),
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("content_md5"), knownvalue.StringExact(checksums.md5Hex)),
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("content_sha256"), knownvalue.StringExact(checksums.sha256Hex)),
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("content_base64sha512"), knownvalue.StringExact(checksums.sha512Base64)),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "hello")
+				},
+			},
+			{
+				// The file was modified outside of Terraform, so the command
+				// is run again in place.
+				PreConfig: func() {
+					if err := os.WriteFile(testFile, []byte("modified"), 0644); err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				},
+				Config: testAccConfigLocalCommandOutputFile(testFile),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command_output_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("content_sha1"), knownvalue.StringExact(checksums.sha1Hex)),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "hello")
+				},
+			},
+			{
+				// The file was removed outside of Terraform, so it is created
+				// again.
+				PreConfig: func() {
+					if err := os.Remove(testFile); err != nil {
+						t.Fatalf("error removing test file: %s", err)
+					}
+				},
+				Config: testAccConfigLocalCommandOutputFile(testFile),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command_output_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "hello")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandOutputFile_permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on Windows")
+	}
+
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+
+	config := func(filePermission string) string {
+		return fmt.Sprintf(`
+resource "local_command_output_file" "test" {
+  filename        = %[1]q
+  file_permission = %[2]q
+  command         = "printf"
+  arguments       = ["%%s", "hello"]
+}`, testFile, filePermission)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		CheckDestroy:             checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				Config: config("0600"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("actual_file_permission"), knownvalue.StringExact("0600")),
+				},
+			},
+			{
+				// Changing the permissions changes them in place.
+				Config: config("0640"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command_output_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("actual_file_permission"), knownvalue.StringExact("0640")),
+				},
+			},
+			{
+				// Permissions changed outside of Terraform are changed back
+				// in place.
+				PreConfig: func() {
+					if err := os.Chmod(testFile, 0604); err != nil {
+						t.Fatalf("error changing test file permissions: %s", err)
+					}
+				},
+				Config: config("0640"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command_output_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("actual_file_permission"), knownvalue.StringExact("0640")),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandOutputFile_drift_policy(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+	checksums := genFileChecksums([]byte("modified"))
+
+	config := func(driftPolicy string) string {
+		return fmt.Sprintf(`
+resource "local_command_output_file" "test" {
+  filename     = %[1]q
+  drift_policy = %[2]q
+  command      = "printf"
+  arguments    = ["%%s", "hello"]
+}`, testFile, driftPolicy)
+	}
+
+	modifyFile := func() {
+		if err := os.WriteFile(testFile, []byte("modified"), 0644); err != nil {
+			t.Fatalf("error writing test file: %s", err)
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		CheckDestroy:             checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				Config: config("ignore"),
+			},
+			{
+				// The modified file is kept, and its checksums are recorded.
+				PreConfig: modifyFile,
+				Config:    config("ignore"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command_output_file.test", tfjsonpath.New("content_sha1"), knownvalue.StringExact(checksums.sha1Hex)),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "modified")
+				},
+			},
+			{
+				Config:      config("error"),
+				ExpectError: regexp.MustCompile(`Local File Changed Outside of Terraform`),
+			},
+			{
+				// Refreshing succeeds again once the file has been restored.
+				PreConfig: func() {
+					if err := os.WriteFile(testFile, []byte("hello"), 0644); err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				},
+				Config: config("recreate"),
+			},
+		},
+	})
+}
+
+func testAccConfigLocalCommandOutputFile(filename string) string {
+	return fmt.Sprintf(`
+resource "local_command_output_file" "test" {
+  filename  = %[1]q
+  command   = "printf"
+  arguments = ["%%s", "hello"]
+}`, filename)
+}
+
+func TestLocalCommandOutputFile_non_zero_exit_code_error(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		// The partial output of the failed command is never written to the file.
+		CheckDestroy: checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "local_command_output_file" "test" {
+  filename  = %q
+  command   = "sh"
+  arguments = ["-c", "echo partial; exit 1"]
+}`, testFile),
+				ExpectError: regexp.MustCompile(`The resource executed the command but received a non-zero exit code`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandOutputFile_allowed_paths(t *testing.T) {
+	allowedDirPath := t.TempDir()
+	outsideFilePath := filepath.Join(t.TempDir(), "test_file.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccConfigProviderAllowedPaths(allowedDirPath) + testAccConfigLocalCommandOutputFile(outsideFilePath),
+				ExpectError: regexp.MustCompile(`Path Not Allowed`),
+			},
+		},
+	})
+}
+
+func TestCreateLocalFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		failed          bool
+		expectedContent string
+	}{
+		"succeeded": {
+			expectedContent: "new",
+		},
+		"failed": {
+			// The previous file is left intact.
+			failed:          true,
+			expectedContent: "previous",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			destination := filepath.Join(dir, "test_file.txt")
+
+			if err := os.WriteFile(destination, []byte("previous"), 0644); err != nil {
+				t.Fatalf("error writing test file: %s", err)
+			}
+
+			output, err := createLocalFile(destination, 0644, 0755)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if _, err := output.Write([]byte("partial")); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if err := output.Reset(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if _, err := output.Write([]byte("new")); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// The destination file is only replaced once the writer is
+			// closed.
+			if err := assertTestFile(t, destination, "previous"); err != nil {
+				t.Fatal(err)
+			}
+
+			if diags := output.close(testCase.failed); diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			if err := assertTestFile(t, destination, testCase.expectedContent); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(entries) != 1 {
+				t.Errorf("expected only the destination file to remain, got %d files", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/config"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestLocalCommandResource_lifecycle(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		CheckDestroy:             checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigLocalCommandResource("v1", testFile),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(0)),
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact(testFile)),
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stderr"), knownvalue.Null()),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v1")
+				},
+			},
+			{
+				Config: testAccConfigLocalCommandResource("v2", testFile),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact(testFile)),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v2")
+				},
+			},
+		},
+	})
+}
+
+func testAccConfigLocalCommandResource(content, filename string) string {
+	return fmt.Sprintf(`
+resource "local_command" "test" {
+  create {
+    command   = "bash"
+    arguments = ["-c", "printf %%s \"$1\" > \"$2\" && printf %%s \"$2\"", "create", %[1]q, %[2]q]
+  }
+
+  read {
+    command   = "bash"
+    arguments = ["-c", "test -f \"$(cat)\" && printf %%s %[2]q"]
+  }
+
+  update {
+    command   = "bash"
+    arguments = ["-c", "printf %%s \"$1\" > \"$(cat)\" && printf %%s %[2]q", "update", %[1]q]
+  }
+
+  destroy {
+    command   = "bash"
+    arguments = ["-c", "rm \"$(cat)\""]
+  }
+}`, content, filename)
+}
+
+func TestLocalCommandResource_read_removed(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigLocalCommandResource("v1", testFile),
+			},
+			{
+				// The read command exits with a non-zero exit code once the
+				// file is removed, so the resource is created again.
+				PreConfig: func() {
+					if err := os.Remove(testFile); err != nil {
+						t.Fatalf("error removing test file: %s", err)
+					}
+				},
+				Config: testAccConfigLocalCommandResource("v1", testFile),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v1")
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_replace(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "local_command" "test" {
+					triggers = {
+						version = "1"
+					}
+
+					create {
+						command   = "echo"
+						arguments = ["hello"]
+					}
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("hello\n")),
+				},
+			},
+			{
+				// Without an update command, changing the create command
+				// replaces the resource.
+				Config: `resource "local_command" "test" {
+					triggers = {
+						version = "1"
+					}
+
+					create {
+						command   = "echo"
+						arguments = ["world"]
+					}
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("world\n")),
+				},
+			},
+			{
+				Config: `resource "local_command" "test" {
+					triggers = {
+						version = "2"
+					}
+
+					create {
+						command   = "echo"
+						arguments = ["world"]
+					}
+
+					update {
+						command = "cat"
+					}
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+			},
+			{
+				// Only the create command runs an update.
+				Config: `resource "local_command" "test" {
+					triggers = {
+						version = "2"
+					}
+
+					create {
+						command   = "echo"
+						arguments = ["world"]
+					}
+
+					update {
+						command   = "echo"
+						arguments = ["updated"]
+					}
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.StringExact("world\n")),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_missing_command(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "local_command" "test" {
+					create {
+						command = "echo"
+					}
+
+					destroy {
+						arguments = ["hello"]
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The "command" attribute must be configured in the "destroy" block`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_non_zero_exit_code_error(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "local_command" "test" {
+					create {
+						command   = "bash"
+						arguments = ["-c", "echo 'failed' >&2; exit 1"]
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The resource executed the command but received a non-zero exit code`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_create_failed_tainted(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	// The create command fails the first time it is run, after having
+	// created the marker file.
+	configuration := fmt.Sprintf(`resource "local_command" "test" {
+		create {
+			command   = "bash"
+			arguments = ["-c", "test -e \"$MARKER\" && exit 0; touch \"$MARKER\"; exit 3"]
+			environment = {
+				MARKER = %[1]q
+			}
+		}
+	}`, filepath.ToSlash(marker))
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      configuration,
+				ExpectError: regexp.MustCompile(`The resource executed the command but received a non-zero exit code`),
+			},
+			{
+				// The resource was saved despite the failure, and is
+				// replaced because it is tainted.
+				Config: configuration,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("exit_code"), knownvalue.Int64Exact(0)),
+				},
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_allowed_commands(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `provider "local" {
+					allowed_commands = ["echo"]
+				}
+
+				resource "local_command" "test" {
+					create {
+						command = "echo"
+					}
+
+					destroy {
+						command   = "bash"
+						arguments = ["-c", "echo hello"]
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The command is not allowed by the allowed_commands provider configuration`),
+			},
+		},
+	})
+}
+
+// Test is dependent on: https://github.com/jqlang/jq
+func TestLocalCommandResource_json_protocol(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	testScriptsDir := filepath.Join(wd, "testdata", t.Name(), "scripts")
+	testFile := filepath.Join(t.TempDir(), "test_file.txt")
+
+	variables := func(content string) config.Variables {
+		return config.Variables{
+			"filename":            config.StringVariable(testFile),
+			"content":             config.StringVariable(content),
+			"scripts_folder_path": config.StringVariable(testScriptsDir),
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		CheckDestroy:             checkFileDeleted(testFile),
+		Steps: []resource.TestStep{
+			{
+				ConfigDirectory: config.TestNameDirectory(),
+				ConfigVariables: variables("v1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						// The state is planned by the plan command.
+						plancheck.ExpectKnownValue("local_command.test", tfjsonpath.New("state").AtMapKey("content"), knownvalue.StringExact("v1")),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("state"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"filename": knownvalue.StringExact(testFile),
+						"content":  knownvalue.StringExact("v1"),
+					})),
+					// The response holds private data, so it is not persisted.
+					statecheck.ExpectKnownValue("local_command.test", tfjsonpath.New("stdout"), knownvalue.Null()),
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v1")
+				},
+			},
+			{
+				ConfigDirectory: config.TestNameDirectory(),
+				ConfigVariables: variables("v2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v2")
+				},
+			},
+			{
+				// The read command returns the file content, so a modified
+				// file is updated again.
+				PreConfig: func() {
+					if err := os.WriteFile(testFile, []byte("modified"), 0644); err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				},
+				ConfigDirectory: config.TestNameDirectory(),
+				ConfigVariables: variables("v2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_command.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					return assertTestFile(t, testFile, "v2")
+				},
+			},
+			{
+				ConfigDirectory: config.TestNameDirectory(),
+				ConfigVariables: variables(""),
+				ExpectError:     regexp.MustCompile(`The file content must not be empty`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_input_without_json_protocol(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "local_command" "test" {
+					input = {
+						name = "test"
+					}
+
+					create {
+						command = "echo"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`The "input" attribute can only be configured when "protocol" is set to "json"`),
+			},
+		},
+	})
+}
+
+func TestLocalCommandResource_invalid_response(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "local_command" "test" {
+					protocol = "json"
+
+					create {
+						command   = "echo"
+						arguments = ["not json"]
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Command Response`),
+			},
+		},
+	})
+}
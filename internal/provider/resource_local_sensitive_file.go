@@ -5,20 +5,20 @@ package provider
 
 import (
 	"context"
-	"crypto/sha1"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -27,14 +27,40 @@ import (
 )
 
 var (
-	_ resource.Resource = (*localSensitiveFileResource)(nil)
+	_ resource.Resource                = (*localSensitiveFileResource)(nil)
+	_ resource.ResourceWithConfigure   = (*localSensitiveFileResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*localSensitiveFileResource)(nil)
+	_ resource.ResourceWithImportState = (*localSensitiveFileResource)(nil)
 )
 
+// localSensitiveFileDefaultPermission is the default of the file_permission and
+// directory_permission attributes.
+const localSensitiveFileDefaultPermission = "0700"
+
 func NewLocalSensitiveFileResource() resource.Resource {
 	return &localSensitiveFileResource{}
 }
 
-type localSensitiveFileResource struct{}
+type localSensitiveFileResource struct {
+	providerData *localProviderData
+}
+
+func (n *localSensitiveFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*localProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *localProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	n.providerData = providerData
+}
 
 func (n *localSensitiveFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -97,53 +123,161 @@ func (n *localSensitiveFileResource) Schema(ctx context.Context, req resource.Sc
 				CustomType: localtypes.NewFilePermissionType(),
 				Description: "Permissions to set for the output file (before umask), expressed as string in\n " +
 					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).\n " +
+					"Changing it changes the permissions of the file in place.\n " +
 					"Default value is `\"0700\"`.",
 				Optional: true,
 				Computed: true,
-				Default:  stringdefault.StaticString("0700"),
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					filePermissionDefault(localSensitiveFileDefaultPermission),
 				},
 			},
 			"directory_permission": schema.StringAttribute{
 				CustomType: localtypes.NewFilePermissionType(),
 				Description: "Permissions to set for directories created (before umask), expressed as string in\n " +
 					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).\n " +
+					"Changing it does not change the permissions of existing directories.\n " +
 					"Default value is `\"0700\"`.",
 				Optional: true,
 				Computed: true,
-				Default:  stringdefault.StaticString("0700"),
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					filePermissionDefault(localSensitiveFileDefaultPermission),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Description: "The name or numeric ID of the user to own the file.\n " +
+					"Changing it changes the owner of the file in place.\n " +
+					"Changing the owner of a file typically requires Terraform to run as root, and is not supported on Windows.\n " +
+					"If not set, the file is owned by the user Terraform runs as when it is written.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"group": schema.StringAttribute{
+				Description: "The name or numeric ID of the group to own the file.\n " +
+					"Changing it changes the group of the file in place.\n " +
+					"Not supported on Windows.\n " +
+					"If not set, the group is chosen by the operating system when the file is written.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"directory_owner": schema.StringAttribute{
+				Description: "The name or numeric ID of the user to own directories created.\n " +
+					"Changing it does not change the owner of existing directories.\n " +
+					"Not supported on Windows.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"directory_group": schema.StringAttribute{
+				Description: "The name or numeric ID of the group to own directories created.\n " +
+					"Changing it does not change the group of existing directories.\n " +
+					"Not supported on Windows.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"write_mode": schema.StringAttribute{
+				Description: "How the file is written.\n " +
+					"With `atomic`, the content is written to a temporary file in the same directory, which is synced to disk\n " +
+					"and then renamed over the file, so that readers never see a partially written file.\n " +
+					"This replaces, rather than writes through, a symbolic link at `filename`.\n " +
+					"With `direct`, the content is written to the file directly, for filesystems where renaming over a file\n " +
+					"is not supported or not atomic.\n " +
+					"Default value is `\"atomic\"`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(writeModeAtomic, writeModeDirect),
+				},
+			},
+			"sync_directory": schema.BoolAttribute{
+				Description: "Whether the parent directory is synced to disk once the file has been written,\n " +
+					"so that the new file survives a crash of the machine.\n " +
+					"Default value is `false`.",
+				Optional: true,
+			},
+			"drift_policy": schema.StringAttribute{
+				Description: "What happens when refreshing finds that the file was changed outside of Terraform.\n " +
+					"The checksums, `size` and `actual_file_permission` of the file on disk are always recorded,\n " +
+					"so the changes are shown by the plan, while `id` remains the SHA1 checksum of the content written by Terraform.\n " +
+					"With `recreate`, changed content is rewritten and changed permissions are changed back by an in-place update,\n " +
+					"for which the permissions of the file are recorded as its `file_permission`.\n " +
+					"With `ignore`, the changed file is kept.\n " +
+					"With `error`, refreshing fails, including when the file was deleted, which is otherwise created again.\n " +
+					"The policy in the state is used when refreshing, so changing it from `error` requires planning with `-refresh=false`.\n " +
+					"Default value is `\"recreate\"`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(driftPolicyRecreate, driftPolicyIgnore, driftPolicyError),
 				},
 			},
 			"id": schema.StringAttribute{
-				Description: "The hexadecimal encoding of the SHA1 checksum of the file content.",
+				Description: "The hexadecimal encoding of the SHA1 checksum of the file content written by Terraform.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_md5": schema.StringAttribute{
 				Description: "MD5 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_sha1": schema.StringAttribute{
 				Description: "SHA1 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_sha256": schema.StringAttribute{
 				Description: "SHA256 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_base64sha256": schema.StringAttribute{
 				Description: "Base64 encoded SHA256 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_sha512": schema.StringAttribute{
 				Description: "SHA512 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"content_base64sha512": schema.StringAttribute{
 				Description: "Base64 encoded SHA512 checksum of file content.",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Description: "The size of the file in bytes.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"actual_file_permission": schema.StringAttribute{
+				Description: "Permissions of the file (after umask), expressed as string in\n " +
+					"[numeric notation](https://en.wikipedia.org/wiki/File-system_permissions#Numeric_notation).",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -153,65 +287,70 @@ func (n *localSensitiveFileResource) Metadata(ctx context.Context, req resource.
 	resp.TypeName = req.ProviderTypeName + "_sensitive_file"
 }
 
-func (n *localSensitiveFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan localSensitiveFileResourceModelV0
+func (n *localSensitiveFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing is written when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
 
-	var filePerm, dirPerm string
+	var plan localSensitiveFileResourceModelV0
 
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	content, err := parseLocalSensitiveFileContent(plan)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Create local sensitive file error",
-			"An unexpected error occurred while parsing local file content\n\n+"+
-				fmt.Sprintf("Original Error: %s", err),
-		)
+	resp.Diagnostics.Append(n.checkPaths(plan)...)
+	resp.Diagnostics.Append(n.checkOwners(plan)...)
+
+	if req.State.Raw.IsNull() {
 		return
 	}
 
-	destination := plan.Filename.ValueString()
+	var state localSensitiveFileResourceModelV0
 
-	destinationDir := filepath.Dir(destination)
-	if _, err := os.Stat(destinationDir); err != nil {
-		dirPerm = plan.DirectoryPermission.ValueString()
-		dirMode, _ := strconv.ParseInt(dirPerm, 8, 64)
-		if err := os.MkdirAll(destinationDir, os.FileMode(dirMode)); err != nil {
-			resp.Diagnostics.AddError(
-				"Create local sensitive file error",
-				"An unexpected error occurred while creating file directory\n\n+"+
-					fmt.Sprintf("Original Error: %s", err),
-			)
-			return
-		}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	filePerm = plan.FilePermission.ValueString()
+	// Changing the permissions changes them in place.
+	if !plan.FilePermission.Equal(state.FilePermission) {
+		plan.ActualFilePermission = types.StringUnknown()
 
-	fileMode, _ := strconv.ParseInt(filePerm, 8, 64)
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
 
-	if err := os.WriteFile(destination, content, os.FileMode(fileMode)); err != nil {
-		resp.Diagnostics.AddError(
-			"Create local sensitive file error",
-			"An unexpected error occurred while writing the file\n\n+"+
-				fmt.Sprintf("Original Error: %s", err),
-		)
+	// A file which was changed outside of Terraform is rewritten by an
+	// in-place update, unless the changes are ignored.
+	if state.drifted() && plan.DriftPolicy.ValueString() != driftPolicyIgnore {
+		plan.ContentMd5 = types.StringUnknown()
+		plan.ContentSha1 = types.StringUnknown()
+		plan.ContentSha256 = types.StringUnknown()
+		plan.ContentBase64sha256 = types.StringUnknown()
+		plan.ContentSha512 = types.StringUnknown()
+		plan.ContentBase64sha512 = types.StringUnknown()
+		plan.Size = types.Int64Unknown()
+		plan.ActualFilePermission = types.StringUnknown()
+
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+}
+
+func (n *localSensitiveFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan localSensitiveFileResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	checksums := genFileChecksums(content)
-	plan.ContentMd5 = types.StringValue(checksums.md5Hex)
-	plan.ContentSha1 = types.StringValue(checksums.sha1Hex)
-	plan.ContentSha256 = types.StringValue(checksums.sha256Hex)
-	plan.ContentBase64sha256 = types.StringValue(checksums.sha256Base64)
-	plan.ContentSha512 = types.StringValue(checksums.sha512Hex)
-	plan.ContentBase64sha512 = types.StringValue(checksums.sha512Base64)
+	resp.Diagnostics.Append(n.write(&plan, "Create local sensitive file error")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	plan.ID = types.StringValue(checksums.sha1Hex)
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 }
@@ -225,17 +364,21 @@ func (n *localSensitiveFileResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	// If the output file doesn't exist, mark the resource for creation.
 	outputPath := state.Filename.ValueString()
-	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
+	status, err := readLocalFileStatus(outputPath)
+	if os.IsNotExist(err) {
+		if state.DriftPolicy.ValueString() == driftPolicyError {
+			resp.Diagnostics.AddError(
+				"Local File Changed Outside of Terraform",
+				fmt.Sprintf("The file %q was deleted outside of Terraform, and \"drift_policy\" is %q.", outputPath, driftPolicyError),
+			)
+			return
+		}
+
+		// If the output file doesn't exist, mark the resource for creation.
 		resp.State.RemoveResource(ctx)
 		return
 	}
-
-	// Verify that the content of the destination file matches the content we
-	// expect. Otherwise, the file might have been modified externally, and we
-	// must reconcile.
-	outputContent, err := os.ReadFile(outputPath)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Read local sensitive file error",
@@ -245,18 +388,86 @@ func (n *localSensitiveFileResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	outputChecksum := sha1.Sum(outputContent)
-	if hex.EncodeToString(outputChecksum[:]) != state.ID.ValueString() {
-		resp.State.RemoveResource(ctx)
+	// The permissions are compared with those the file had when it was last
+	// written or refreshed, as they depend on the umask.
+	expectedPerm := state.ActualFilePermission.ValueString()
+	permDrifted := !state.ActualFilePermission.IsNull() && expectedPerm != formatFilePermission(status.perm)
+
+	// An owner which can no longer be resolved is not compared, as it is
+	// reported by the next plan.
+	expectedOwner, _ := resolveFileOwner(state.Owner, state.Group)
+	ownerDrifted := expectedOwner.differs(status.owner)
+
+	// Record the file as it is on disk, so that the plan shows any changes
+	// made outside of Terraform. The ID remains the checksum of the content
+	// written by Terraform.
+	state.setStatus(status)
+
+	if (state.drifted() || permDrifted || ownerDrifted) && state.DriftPolicy.ValueString() == driftPolicyError {
+		detail := fmt.Sprintf("The file %q was changed outside of Terraform, and \"drift_policy\" is %q.", outputPath, driftPolicyError) +
+			"\n\n" +
+			fmt.Sprintf("Expected SHA1: %s\n", state.ID.ValueString()) +
+			fmt.Sprintf("Actual SHA1: %s\n", status.checksums.sha1Hex) +
+			fmt.Sprintf("Actual Size: %d bytes\n", status.size) +
+			fmt.Sprintf("Expected Permissions: %s\n", expectedPerm) +
+			fmt.Sprintf("Actual Permissions: %s", formatFilePermission(status.perm))
+
+		if ownerDrifted {
+			detail += "\n" +
+				fmt.Sprintf("Expected Owner: %s:%s\n", state.Owner.ValueString(), state.Group.ValueString()) +
+				fmt.Sprintf("Actual Owner: %d:%d", status.owner.uid, status.owner.gid)
+		}
+
+		resp.Diagnostics.AddError("Local File Changed Outside of Terraform", detail)
 		return
 	}
+
+	// Permissions which were changed outside of Terraform are recorded as the
+	// configured permissions, so that they are changed back in place.
+	if permDrifted && state.DriftPolicy.ValueString() != driftPolicyIgnore {
+		state.FilePermission = localtypes.FilePermissionValue{StringValue: types.StringValue(formatFilePermission(status.perm))}
+	}
+
+	// Likewise, an owner which was changed outside of Terraform is recorded as
+	// the numeric ID of the configured owner.
+	if ownerDrifted && state.DriftPolicy.ValueString() != driftPolicyIgnore {
+		if expectedOwner.uid != -1 && expectedOwner.uid != status.owner.uid {
+			state.Owner = types.StringValue(strconv.Itoa(status.owner.uid))
+		}
+
+		if expectedOwner.gid != -1 && expectedOwner.gid != status.owner.gid {
+			state.Group = types.StringValue(strconv.Itoa(status.owner.gid))
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (n *localSensitiveFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan localSensitiveFileResourceModelV0
+	var plan, state localSensitiveFileResourceModelV0
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.drifted() && plan.DriftPolicy.ValueString() != driftPolicyIgnore {
+		resp.Diagnostics.Append(n.write(&plan, "Update local sensitive file error")...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The permissions are changed in place, which is also needed after writing
+	// directly to the existing file, as that does not change them.
+	resp.Diagnostics.Append(n.chmod(&plan, state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	resp.Diagnostics.Append(n.chown(plan, state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -267,9 +478,287 @@ func (n *localSensitiveFileResource) Update(ctx context.Context, req resource.Up
 func (n *localSensitiveFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var filename string
 	req.State.GetAttribute(ctx, path.Root("filename"), &filename)
+
+	// A file which is no longer within the allowed paths is not removed.
+	resp.Diagnostics.Append(n.providerData.checkPath(path.Root("filename"), filename)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	os.Remove(filename)
 }
 
+// ImportState adopts the existing file at the path given as the import ID.
+// Its content is imported as content if it is valid UTF-8, and as
+// content_base64 otherwise. The file_permission and directory_permission
+// attributes are left null, as the permissions before the umask cannot be read
+// from the file, and are only set once configured, see filePermissionDefault.
+func (n *localSensitiveFileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(n.providerData.checkPath(path.Root("filename"), req.ID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := os.ReadFile(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Import local sensitive file error",
+			"An unexpected error occurred while reading the file\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return
+	}
+
+	info, err := os.Stat(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Import local sensitive file error",
+			"An unexpected error occurred while reading the file permissions\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return
+	}
+
+	state := localSensitiveFileResourceModelV0{
+		Filename: types.StringValue(req.ID),
+	}
+
+	if utf8.Valid(content) {
+		state.Content = types.StringValue(string(content))
+	} else {
+		state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+	}
+
+	state.setStatus(&localFileStatus{
+		checksums: genFileChecksums(content),
+		size:      int64(len(content)),
+		perm:      info.Mode().Perm(),
+	})
+	state.ID = state.ContentSha1
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// write writes the content of the plan to the destination file, creating its
+// directory if needed, and records the written file in the plan. Errors are
+// reported with the given summary.
+func (n *localSensitiveFileResource) write(plan *localSensitiveFileResourceModelV0, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(n.checkPaths(*plan)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	content, err := parseLocalSensitiveFileContent(*plan)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while parsing local file content\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	owner, err := resolveFileOwner(plan.Owner, plan.Group)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while resolving the file owner\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	dirOwner, err := resolveFileOwner(plan.DirectoryOwner, plan.DirectoryGroup)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while resolving the directory owner\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	destination := plan.Filename.ValueString()
+
+	destinationDir := filepath.Dir(destination)
+	if _, err := os.Stat(destinationDir); err != nil {
+		dirMode := parseFilePermission(plan.DirectoryPermission, localSensitiveFileDefaultPermission)
+		if err := mkdirAll(destinationDir, dirMode, dirOwner); err != nil {
+			diags.AddError(
+				summary,
+				"An unexpected error occurred while creating file directory\n\n+"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return diags
+		}
+	}
+
+	fileMode := parseFilePermission(plan.FilePermission, localSensitiveFileDefaultPermission)
+
+	if err := writeLocalFile(destination, content, fileMode, owner, plan.WriteMode.ValueString(), plan.SyncDirectory.ValueBool()); err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while writing the file\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"An unexpected error occurred while reading the file permissions\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+		return diags
+	}
+
+	plan.setStatus(&localFileStatus{
+		checksums: genFileChecksums(content),
+		size:      int64(len(content)),
+		perm:      info.Mode().Perm(),
+	})
+	plan.ID = plan.ContentSha1
+
+	return diags
+}
+
+// chmod changes the permissions of the destination file in place if they
+// differ between the state and the plan, and records them in the plan.
+func (n *localSensitiveFileResource) chmod(plan *localSensitiveFileResourceModelV0, state localSensitiveFileResourceModelV0) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !plan.FilePermission.Equal(state.FilePermission) {
+		diags.Append(n.checkPaths(*plan)...)
+		if diags.HasError() {
+			return diags
+		}
+
+		fileMode := parseFilePermission(plan.FilePermission, localSensitiveFileDefaultPermission)
+
+		perm, err := chmodLocalFile(plan.Filename.ValueString(), fileMode)
+		if err != nil {
+			diags.AddError(
+				"Update local sensitive file error",
+				"An unexpected error occurred while changing the file permissions\n\n+"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return diags
+		}
+
+		plan.ActualFilePermission = types.StringValue(formatFilePermission(perm))
+	}
+
+	// The state was written by a version of the provider which did not
+	// record the size and permissions, and was not refreshed.
+	if plan.Size.IsUnknown() || plan.ActualFilePermission.IsUnknown() {
+		status, err := readLocalFileStatus(plan.Filename.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Update local sensitive file error",
+				"An unexpected error occurred while reading the file\n\n+"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return diags
+		}
+
+		plan.Size = types.Int64Value(status.size)
+		plan.ActualFilePermission = types.StringValue(formatFilePermission(status.perm))
+	}
+
+	return diags
+}
+
+// chown changes the owner of the destination file in place if it differs
+// between the state and the plan. A file whose owner is no longer configured
+// keeps its owner.
+func (n *localSensitiveFileResource) chown(plan localSensitiveFileResourceModelV0, state localSensitiveFileResourceModelV0) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if plan.Owner.Equal(state.Owner) && plan.Group.Equal(state.Group) {
+		return diags
+	}
+
+	diags.Append(n.checkPaths(plan)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	owner, err := resolveFileOwner(plan.Owner, plan.Group)
+	if err == nil {
+		err = owner.chown(plan.Filename.ValueString())
+	}
+	if err != nil {
+		diags.AddError(
+			"Update local sensitive file error",
+			"An unexpected error occurred while changing the file owner\n\n+"+
+				fmt.Sprintf("Original Error: %s", err),
+		)
+	}
+
+	return diags
+}
+
+// checkPaths verifies that the destination and source files of the plan
+// are within the allowed paths of the provider configuration. Unknown
+// values are skipped, as they are checked again during apply.
+func (n *localSensitiveFileResource) checkPaths(plan localSensitiveFileResourceModelV0) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !plan.Filename.IsUnknown() {
+		diags.Append(n.providerData.checkPath(path.Root("filename"), plan.Filename.ValueString())...)
+	}
+
+	if !plan.Source.IsNull() && !plan.Source.IsUnknown() {
+		diags.Append(n.providerData.checkPath(path.Root("source"), plan.Source.ValueString())...)
+	}
+
+	return diags
+}
+
+// checkOwners verifies that the owners and groups of the plan can be
+// resolved. Unknown values are skipped, as they are resolved again during
+// apply.
+func (n *localSensitiveFileResource) checkOwners(plan localSensitiveFileResourceModelV0) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	owners := []struct {
+		user      types.String
+		userPath  path.Path
+		group     types.String
+		groupPath path.Path
+	}{
+		{plan.Owner, path.Root("owner"), plan.Group, path.Root("group")},
+		{plan.DirectoryOwner, path.Root("directory_owner"), plan.DirectoryGroup, path.Root("directory_group")},
+	}
+
+	for _, owner := range owners {
+		if owner.user.IsUnknown() || owner.group.IsUnknown() {
+			continue
+		}
+
+		if _, err := resolveFileOwner(owner.user, owner.group); err != nil {
+			attributePath := owner.userPath
+			if owner.user.IsNull() {
+				attributePath = owner.groupPath
+			}
+
+			diags.AddAttributeError(
+				attributePath,
+				"Invalid Owner",
+				"The user or group to own the file cannot be resolved."+
+					"\n\n"+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+		}
+	}
+
+	return diags
+}
+
 func parseLocalSensitiveFileContent(plan localSensitiveFileResourceModelV0) ([]byte, error) {
 	if !plan.ContentBase64.IsNull() && !plan.ContentBase64.IsUnknown() {
 		return base64.StdEncoding.DecodeString(plan.ContentBase64.ValueString())
@@ -285,17 +774,44 @@ func parseLocalSensitiveFileContent(plan localSensitiveFileResourceModelV0) ([]b
 }
 
 type localSensitiveFileResourceModelV0 struct {
-	Filename            types.String                   `tfsdk:"filename"`
-	Content             types.String                   `tfsdk:"content"`
-	ContentBase64       types.String                   `tfsdk:"content_base64"`
-	Source              types.String                   `tfsdk:"source"`
-	FilePermission      localtypes.FilePermissionValue `tfsdk:"file_permission"`
-	DirectoryPermission localtypes.FilePermissionValue `tfsdk:"directory_permission"`
-	ID                  types.String                   `tfsdk:"id"`
-	ContentMd5          types.String                   `tfsdk:"content_md5"`
-	ContentSha1         types.String                   `tfsdk:"content_sha1"`
-	ContentSha256       types.String                   `tfsdk:"content_sha256"`
-	ContentBase64sha256 types.String                   `tfsdk:"content_base64sha256"`
-	ContentSha512       types.String                   `tfsdk:"content_sha512"`
-	ContentBase64sha512 types.String                   `tfsdk:"content_base64sha512"`
+	Filename             types.String                   `tfsdk:"filename"`
+	Content              types.String                   `tfsdk:"content"`
+	ContentBase64        types.String                   `tfsdk:"content_base64"`
+	Source               types.String                   `tfsdk:"source"`
+	FilePermission       localtypes.FilePermissionValue `tfsdk:"file_permission"`
+	DirectoryPermission  localtypes.FilePermissionValue `tfsdk:"directory_permission"`
+	Owner                types.String                   `tfsdk:"owner"`
+	Group                types.String                   `tfsdk:"group"`
+	DirectoryOwner       types.String                   `tfsdk:"directory_owner"`
+	DirectoryGroup       types.String                   `tfsdk:"directory_group"`
+	WriteMode            types.String                   `tfsdk:"write_mode"`
+	SyncDirectory        types.Bool                     `tfsdk:"sync_directory"`
+	DriftPolicy          types.String                   `tfsdk:"drift_policy"`
+	ID                   types.String                   `tfsdk:"id"`
+	ContentMd5           types.String                   `tfsdk:"content_md5"`
+	ContentSha1          types.String                   `tfsdk:"content_sha1"`
+	ContentSha256        types.String                   `tfsdk:"content_sha256"`
+	ContentBase64sha256  types.String                   `tfsdk:"content_base64sha256"`
+	ContentSha512        types.String                   `tfsdk:"content_sha512"`
+	ContentBase64sha512  types.String                   `tfsdk:"content_base64sha512"`
+	Size                 types.Int64                    `tfsdk:"size"`
+	ActualFilePermission types.String                   `tfsdk:"actual_file_permission"`
+}
+
+// setStatus records the file as read from disk.
+func (m *localSensitiveFileResourceModelV0) setStatus(status *localFileStatus) {
+	m.ContentMd5 = types.StringValue(status.checksums.md5Hex)
+	m.ContentSha1 = types.StringValue(status.checksums.sha1Hex)
+	m.ContentSha256 = types.StringValue(status.checksums.sha256Hex)
+	m.ContentBase64sha256 = types.StringValue(status.checksums.sha256Base64)
+	m.ContentSha512 = types.StringValue(status.checksums.sha512Hex)
+	m.ContentBase64sha512 = types.StringValue(status.checksums.sha512Base64)
+	m.Size = types.Int64Value(status.size)
+	m.ActualFilePermission = types.StringValue(formatFilePermission(status.perm))
+}
+
+// drifted reports whether the content of the file, as last read from disk,
+// differs from the content written by Terraform.
+func (m *localSensitiveFileResourceModelV0) drifted() bool {
+	return !m.ContentSha1.IsNull() && !m.ContentSha1.IsUnknown() && m.ContentSha1.ValueString() != m.ID.ValueString()
 }
@@ -12,6 +12,8 @@ import (
 	"testing"
 
 	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestLocalSensitiveFile_Basic(t *testing.T) {
@@ -109,6 +111,219 @@ func TestLocalSensitiveFile_Permissions(t *testing.T) {
 	})
 }
 
+func TestLocalSensitiveFile_Permissions_Update(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "local_sensitive_file")
+	f = strings.ReplaceAll(f, `\`, `\\`)
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				SkipFunc: skipTestsWindows(),
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  file_permission = "0400"
+					}`, f),
+				Check: r.TestCheckResourceAttr("local_sensitive_file.file", "actual_file_permission", "0400"),
+			},
+			{
+				// Changing the permissions does not replace the file.
+				SkipFunc: skipTestsWindows(),
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  file_permission = "0600"
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("local_sensitive_file.file", "actual_file_permission", "0600"),
+					checkFilePermissions(f),
+				),
+			},
+			{
+				// Permissions changed outside of Terraform are changed back.
+				PreConfig: func() {
+					if err := os.Chmod(f, 0400); err != nil {
+						t.Fatal(err)
+					}
+				},
+				SkipFunc: skipTestsWindows(),
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  file_permission = "0600"
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: checkFilePermissions(f),
+			},
+		},
+		CheckDestroy: checkFileDeleted(f),
+	})
+}
+
+func TestLocalSensitiveFile_Import(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "local_sensitive_file")
+	f = strings.ReplaceAll(f, `\`, `\\`)
+
+	r.UnitTest(t, r.TestCase{
+		// Import blocks were introduced in Terraform 1.5.0.
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_5_0),
+		},
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content  = "This is some content"
+					  filename = %[1]q
+					}`, f),
+			},
+			{
+				// The permissions before the umask cannot be read from the
+				// file, so they are left unset.
+				ResourceName:            "local_sensitive_file.file",
+				ImportState:             true,
+				ImportStateId:           f,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"file_permission", "directory_permission"},
+			},
+			{
+				// A configuration which relies on the default permissions
+				// plans no changes after the import.
+				ResourceName:    "local_sensitive_file.file",
+				ImportState:     true,
+				ImportStateKind: r.ImportBlockWithID,
+				ImportStateId:   f,
+			},
+			{
+				// Content which is not valid UTF-8 is imported as content_base64.
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content_base64  = "//4="
+					  filename        = %[1]q
+					  file_permission = "0600"
+					}`, f),
+			},
+			{
+				ResourceName:            "local_sensitive_file.file",
+				ImportState:             true,
+				ImportStateId:           f,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"file_permission", "directory_permission"},
+			},
+			{
+				// Configured permissions are set by an in-place update.
+				ResourceName:       "local_sensitive_file.file",
+				ImportState:        true,
+				ImportStateKind:    r.ImportBlockWithID,
+				ImportStateId:      f,
+				ExpectNonEmptyPlan: true,
+				ImportPlanChecks: r.ImportPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+		CheckDestroy: checkFileDeleted(f),
+	})
+}
+
+func TestLocalSensitiveFile_Owner(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "directory")
+	f := filepath.Join(dir, "local_sensitive_file")
+
+	skipUnlessRoot := func() (bool, error) {
+		return os.Getuid() != 0, nil
+	}
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				SkipFunc: skipUnlessRoot,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  owner           = "4321"
+					  group           = "4321"
+					  directory_owner = "4321"
+					}`, f),
+				Check: r.ComposeTestCheckFunc(
+					checkFileOwner(f, 4321, 4321),
+					checkFileOwner(dir, 4321, 0),
+				),
+			},
+			{
+				// Changing the owner does not replace the file.
+				SkipFunc: skipUnlessRoot,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  owner           = "1234"
+					  group           = "4321"
+					  directory_owner = "4321"
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: checkFileOwner(f, 1234, 4321),
+			},
+			{
+				// An owner changed outside of Terraform is changed back.
+				PreConfig: func() {
+					if err := os.Chown(f, 0, 0); err != nil {
+						t.Fatal(err)
+					}
+				},
+				SkipFunc: skipUnlessRoot,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content         = "This is some content"
+					  filename        = %[1]q
+					  owner           = "1234"
+					  group           = "4321"
+					  directory_owner = "4321"
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: checkFileOwner(f, 1234, 4321),
+			},
+			{
+				SkipFunc: skipUnlessRoot,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content  = "This is some content"
+					  filename = %[1]q
+					  owner    = "terraform-provider-local-unknown"
+					}`, f),
+				ExpectError: regexp.MustCompile(`Invalid Owner`),
+			},
+		},
+		CheckDestroy: checkFileDeleted(f),
+	})
+}
+
 func TestLocalSensitiveFile_Validators(t *testing.T) {
 	f := filepath.Join(t.TempDir(), "local_file")
 	f = strings.ReplaceAll(f, `\`, `\\`)
@@ -261,6 +476,159 @@ func TestLocalSensitiveFile_Permissions_Upgrade(t *testing.T) {
 	})
 }
 
+func TestLocalSensitiveFile_WriteMode(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "local_sensitive_file")
+	f = strings.ReplaceAll(f, `\`, `\\`)
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content    = "This is some content"
+					  filename   = %[1]q
+					  write_mode = "direct"
+					}`, f),
+				Check: checkFileCreation("local_sensitive_file.file", f),
+			},
+			{
+				// Changing how the file is written does not replace it.
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content        = "This is some content"
+					  filename       = %[1]q
+					  write_mode     = "atomic"
+					  sync_directory = true
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: checkFileCreation("local_sensitive_file.file", f),
+			},
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content    = "This is some other content"
+					  filename   = %[1]q
+					  write_mode = "atomic"
+					}`, f),
+				Check: checkFileCreation("local_sensitive_file.file", f),
+			},
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content    = "This is some content"
+					  filename   = %[1]q
+					  write_mode = "rename"
+					}`, f),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+		CheckDestroy: checkFileDeleted(f),
+	})
+}
+
+func TestLocalSensitiveFile_DriftPolicy(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "local_sensitive_file")
+	f = strings.ReplaceAll(f, `\`, `\\`)
+
+	changeFile := func() {
+		if err := os.WriteFile(f, []byte("This was changed outside of Terraform"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content  = "This is some content"
+					  filename = %[1]q
+					}`, f),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("local_sensitive_file.file", "size", "20"),
+					r.TestCheckResourceAttrPair("local_sensitive_file.file", "id", "local_sensitive_file.file", "content_sha1"),
+				),
+			},
+			{
+				// The changed file is rewritten in place.
+				PreConfig: changeFile,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content  = "This is some content"
+					  filename = %[1]q
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("local_sensitive_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("local_sensitive_file.file", "size", "20"),
+					r.TestCheckResourceAttrPair("local_sensitive_file.file", "id", "local_sensitive_file.file", "content_sha1"),
+					checkFileContent(f, "This is some content"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content      = "This is some content"
+					  filename     = %[1]q
+					  drift_policy = "ignore"
+					}`, f),
+			},
+			{
+				// The changed file is kept, and its checksums are recorded.
+				PreConfig: changeFile,
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content      = "This is some content"
+					  filename     = %[1]q
+					  drift_policy = "ignore"
+					}`, f),
+				ConfigPlanChecks: r.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("local_sensitive_file.file", "size", "37"),
+					r.TestCheckResourceAttr("local_sensitive_file.file", "content_sha1", "8620ad2e3c855b547b2df3ebf3096f9dc99bba50"),
+					checkFileContent(f, "This was changed outside of Terraform"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content      = "This is some content"
+					  filename     = %[1]q
+					  drift_policy = "error"
+					}`, f),
+				ExpectError: regexp.MustCompile(`Local File Changed Outside of Terraform`),
+			},
+			{
+				// Refreshing succeeds again once the file has been restored.
+				PreConfig: func() {
+					if err := os.WriteFile(f, []byte("This is some content"), 0600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: fmt.Sprintf(`
+					resource "local_sensitive_file" "file" {
+					  content  = "This is some content"
+					  filename = %[1]q
+					}`, f),
+			},
+		},
+		CheckDestroy: checkFileDeleted(f),
+	})
+}
+
 func testAccConfigLocalSensitiveSourceFile(source, filename string) string {
 	return fmt.Sprintf(`
 				resource "local_sensitive_file" "file" {
@@ -292,3 +660,29 @@ func testAccConfigLocalSensitiveFileDecodedBase64Content(content, filename strin
 				  filename = %[2]q
 				}`, content, filename)
 }
+
+func TestLocalSensitiveFile_AllowedPaths(t *testing.T) {
+	allowedDirPath := t.TempDir()
+	allowedDirPath = strings.ReplaceAll(allowedDirPath, `\`, `\\`)
+	allowedFilePath := filepath.Join(allowedDirPath, "local_sensitive_file")
+	allowedFilePath = strings.ReplaceAll(allowedFilePath, `\`, `\\`)
+	outsideFilePath := filepath.Join(t.TempDir(), "local_sensitive_file")
+	outsideFilePath = strings.ReplaceAll(outsideFilePath, `\`, `\\`)
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: protoV5ProviderFactories(),
+		Steps: []r.TestStep{
+			{
+				Config: testAccConfigProviderAllowedPaths(allowedDirPath) +
+					testAccConfigLocalSensitiveFileContent("This is some sensitive content", outsideFilePath),
+				ExpectError: regexp.MustCompile(`Path Not Allowed`),
+			},
+			{
+				Config: testAccConfigProviderAllowedPaths(allowedDirPath) +
+					testAccConfigLocalSensitiveFileContent("This is some sensitive content", allowedFilePath),
+				Check: checkFileCreation("local_sensitive_file_resource.test", allowedFilePath),
+			},
+		},
+		CheckDestroy: checkFileDeleted(allowedFilePath),
+	})
+}
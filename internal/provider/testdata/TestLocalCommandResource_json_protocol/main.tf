@@ -0,0 +1,47 @@
+# Copyright IBM Corp. 2017, 2026
+# SPDX-License-Identifier: MPL-2.0
+
+locals {
+  test_script = (
+    # This configuration will get copied to a temporary location without the scripts folder, so for
+    # acceptance tests we pass the folder path from the Go test environment via a variable.
+    # If running manually, there is no need to provide the scripts_folder_path.
+    var.scripts_folder_path != null ?
+    "${var.scripts_folder_path}/file_resource.sh" :
+    "${abspath(path.module)}/scripts/file_resource.sh"
+  )
+}
+
+resource "local_command" "test" {
+  protocol = "json"
+
+  input = {
+    filename = var.filename
+    content  = var.content
+  }
+
+  plan {
+    command   = var.bash_path
+    arguments = [local.test_script]
+  }
+
+  create {
+    command   = var.bash_path
+    arguments = [local.test_script]
+  }
+
+  read {
+    command   = var.bash_path
+    arguments = [local.test_script]
+  }
+
+  update {
+    command   = var.bash_path
+    arguments = [local.test_script]
+  }
+
+  destroy {
+    command   = var.bash_path
+    arguments = [local.test_script]
+  }
+}
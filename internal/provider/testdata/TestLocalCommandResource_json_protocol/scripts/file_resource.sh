@@ -0,0 +1,36 @@
+#!/bin/bash
+# Copyright IBM Corp. 2017, 2026
+# SPDX-License-Identifier: MPL-2.0
+
+# Manages a file with the JSON request and response protocol of local_command.
+# Dependent on: https://github.com/jqlang/jq
+request=$(</dev/stdin)
+
+case $(jq -r .operation <<<"$request") in
+  plan)
+    jq -c '
+      if .config.content == "" then
+        {diagnostics: [{severity: "error", summary: "Invalid Content", detail: "The file content must not be empty."}]}
+      else
+        {
+          state: .config,
+          requires_replace: (.prior_state != null and .prior_state.filename != .config.filename)
+        }
+      end' <<<"$request"
+    ;;
+  create | update)
+    jq -j .config.content <<<"$request" > "$(jq -r .config.filename <<<"$request")"
+    jq -c '{state: .config, private: {writes: ((.private.writes // 0) + 1)}}' <<<"$request"
+    ;;
+  read)
+    filename=$(jq -r .prior_state.filename <<<"$request")
+    if [ -f "$filename" ]; then
+      jq -c --rawfile content "$filename" '{state: {filename: .prior_state.filename, content: $content}}' <<<"$request"
+    else
+      echo '{"state": null}'
+    fi
+    ;;
+  delete)
+    rm -f "$(jq -r .prior_state.filename <<<"$request")"
+    ;;
+esac
@@ -0,0 +1,20 @@
+# Copyright IBM Corp. 2017, 2026
+# SPDX-License-Identifier: MPL-2.0
+
+variable "bash_path" {
+  type    = string
+  default = "bash"
+}
+
+variable "filename" {
+  type = string
+}
+
+variable "content" {
+  type = string
+}
+
+variable "scripts_folder_path" {
+  type    = string
+  default = null
+}
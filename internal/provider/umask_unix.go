@@ -0,0 +1,25 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package provider
+
+import (
+	"os"
+	"syscall"
+)
+
+// umask is the file mode creation mask of the provider process. It cannot be
+// read without changing it for the whole process, which would race with files
+// written concurrently, so it is read once on startup, before any files are
+// written.
+var umask = readUmask()
+
+// readUmask returns the file mode creation mask of the process.
+func readUmask() os.FileMode {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+
+	return os.FileMode(mask)
+}
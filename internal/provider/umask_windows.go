@@ -0,0 +1,13 @@
+// Copyright IBM Corp. 2017, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package provider
+
+import (
+	"os"
+)
+
+// umask is always empty, as Windows has no file mode creation mask.
+var umask os.FileMode